@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/abahnj/rssagg/internal/api"
 	"github.com/abahnj/rssagg/internal/cli"
 	"github.com/abahnj/rssagg/internal/feeds"
 	"github.com/abahnj/rssagg/internal/middleware"
@@ -13,17 +14,37 @@ func registerCommands(commands *cli.Commands) {
 	// User commands
 	commands.Register("login", users.HandlerLogin)
 	commands.Register("register", users.HandlerRegister)
+	commands.Register("logout", users.HandlerLogout)
 	commands.Register("reset", users.HandlerDeleteAllUsers)
 	commands.Register("users", users.HandlerListUsers)
+	commands.Register("apikey", middleware.MiddlewareLoggedIn(users.HandlerAPIKey))
 	
 	// Feed commands
 	commands.Register("agg", feeds.HandlerAggregator)
 	commands.Register("feeds", feeds.HandlerListFeeds)
+	commands.Register("feedhealth", feeds.HandlerFeedHealth)
+	
+	// API server
+	commands.Register("serve", api.HandlerServe)
 	
 	// Protected feed commands (requiring authentication)
 	commands.Register("addfeed", middleware.MiddlewareLoggedIn(feeds.HandlerAddFeed))
 	commands.Register("follow", middleware.MiddlewareLoggedIn(feeds.HandlerFollowFeed))
 	commands.Register("following", middleware.MiddlewareLoggedIn(feeds.HandlerListFollowing))
 	commands.Register("unfollow", middleware.MiddlewareLoggedIn(feeds.HandlerUnfollowFeed))
+	commands.Register("fulltext", middleware.MiddlewareLoggedIn(feeds.HandlerFullText))
+	commands.Register("import", middleware.MiddlewareLoggedIn(feeds.HandlerImport))
+	commands.Register("export", middleware.MiddlewareLoggedIn(feeds.HandlerExport))
 	commands.Register("browse", middleware.MiddlewareLoggedIn(posts.HandlerBrowse))
+	commands.Register("read", middleware.MiddlewareLoggedIn(posts.HandlerRead))
+	commands.Register("unread", middleware.MiddlewareLoggedIn(posts.HandlerUnread))
+	commands.Register("markall", middleware.MiddlewareLoggedIn(posts.HandlerMarkAll))
+	commands.Register("star", middleware.MiddlewareLoggedIn(posts.HandlerStar))
+	commands.Register("unstar", middleware.MiddlewareLoggedIn(posts.HandlerUnstar))
+	commands.Register("starred", middleware.MiddlewareLoggedIn(posts.HandlerStarred))
+	commands.Register("filter", middleware.MiddlewareLoggedIn(posts.HandlerFilter))
+	commands.Register("search", middleware.MiddlewareLoggedIn(posts.HandlerSearch))
+	commands.Register("outfeed", middleware.MiddlewareLoggedIn(posts.HandlerOutfeed))
+	commands.Register("podcasts", middleware.MiddlewareLoggedIn(posts.HandlerPodcasts))
+	commands.Register("download", middleware.MiddlewareLoggedIn(posts.HandlerDownload))
 }
\ No newline at end of file
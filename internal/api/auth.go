@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/users"
+)
+
+// apiKeyScheme is the Authorization scheme for API keys
+const apiKeyScheme = "ApiKey"
+
+// userFromAPIKey identifies the user by the API key in an
+// "Authorization: ApiKey <key>" header
+func (s *Server) userFromAPIKey(r *http.Request) (database.User, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return database.User{}, fmt.Errorf("%w: missing Authorization header", ErrUnauthorized)
+	}
+
+	scheme, key, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, apiKeyScheme) {
+		return database.User{}, fmt.Errorf("%w: expected Authorization: %s <key>", ErrUnauthorized, apiKeyScheme)
+	}
+
+	user, err := s.Users.AuthenticateAPIKey(r.Context(), strings.TrimSpace(key))
+	if errors.Is(err, users.ErrInvalidAPIKey) {
+		return database.User{}, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/feeds"
+	"github.com/google/uuid"
+)
+
+// feedResponse is a feed as returned by the API
+type feedResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	AddedBy   string    `json:"added_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// followResponse is a followed feed as returned by the API
+type followResponse struct {
+	FeedID     uuid.UUID `json:"feed_id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// createFeedRequest is the body of POST /feeds
+type createFeedRequest struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// FetchInterval optionally fixes the refresh interval, e.g. "15m"
+	FetchInterval string `json:"fetch_interval,omitempty"`
+}
+
+// followRequest is the body of POST /follows
+type followRequest struct {
+	URL string `json:"url"`
+}
+
+// handleListFeeds serves GET /feeds with every feed
+func (s *Server) handleListFeeds(w http.ResponseWriter, r *http.Request) {
+	rows, err := s.Feeds.GetAllFeeds(r.Context())
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	response := make([]feedResponse, 0, len(rows))
+	for _, row := range rows {
+		response = append(response, feedResponse{
+			ID:        row.ID,
+			Name:      row.Name,
+			URL:       row.Url,
+			AddedBy:   row.UserName,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleCreateFeed serves POST /feeds, adding a feed (or reusing the one
+// with the same URL) and following it, like the addfeed command
+func (s *Server) handleCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var req createFeedRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	if req.Name == "" || req.URL == "" {
+		respondError(w, http.StatusBadRequest, "name and url are required")
+		return
+	}
+
+	var fetchInterval time.Duration
+	if req.FetchInterval != "" {
+		var err error
+		fetchInterval, err = time.ParseDuration(req.FetchInterval)
+		if err != nil || fetchInterval <= 0 {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("invalid fetch_interval: %s", req.FetchInterval))
+			return
+		}
+	}
+
+	feed, created, err := s.Feeds.AddFeed(r.Context(), req.Name, req.URL, user.ID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	// An existing feed is shared with its other followers, so only a feed
+	// this request created gets the interval
+	if fetchInterval > 0 && created {
+		if err := s.Feeds.SetFetchIntervalOverride(r.Context(), feed.ID, fetchInterval); err != nil {
+			respondServiceError(w, err)
+			return
+		}
+	}
+
+	if _, err := s.Feeds.FollowFeed(r.Context(), feed.Url, user.ID); err != nil && !errors.Is(err, feeds.ErrAlreadyFollowing) {
+		respondServiceError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	respondJSON(w, status, feedResponse{
+		ID:        feed.ID,
+		Name:      feed.Name,
+		URL:       feed.Url,
+		CreatedAt: feed.CreatedAt.Time,
+	})
+}
+
+// handleListFollows serves GET /follows with the feeds the user follows
+func (s *Server) handleListFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	rows, err := s.Feeds.GetFollowedFeeds(r.Context(), user.ID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	response := make([]followResponse, 0, len(rows))
+	for _, row := range rows {
+		response = append(response, followResponse{
+			FeedID:     row.FeedID,
+			Name:       row.FeedName,
+			URL:        row.FeedUrl,
+			FollowedAt: row.CreatedAt.Time,
+		})
+	}
+	respondJSON(w, http.StatusOK, response)
+}
+
+// handleFollow serves POST /follows, following an existing feed by URL
+func (s *Server) handleFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var req followRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	if req.URL == "" {
+		respondError(w, http.StatusBadRequest, "url is required")
+		return
+	}
+
+	follow, err := s.Feeds.FollowFeed(r.Context(), req.URL, user.ID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, followResponse{
+		FeedID:     follow.FeedID,
+		Name:       follow.FeedName,
+		URL:        req.URL,
+		FollowedAt: follow.CreatedAt.Time,
+	})
+}
+
+// handleUnfollow serves DELETE /follows?url=<feed-url>
+func (s *Server) handleUnfollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := r.URL.Query().Get("url")
+	if feedURL == "" {
+		respondError(w, http.StatusBadRequest, "url query parameter is required")
+		return
+	}
+
+	if err := s.Feeds.UnfollowFeed(r.Context(), feedURL, user.ID); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
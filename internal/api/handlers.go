@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/cli"
+)
+
+// shutdownTimeout bounds how long serve waits for in-flight requests on exit
+const shutdownTimeout = 10 * time.Second
+
+// HandlerServe handles the serve command to run the REST API on an address
+func HandlerServe(s *cli.State, cmd cli.Command) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("address is required (e.g. :8080)")
+	}
+
+	server := &http.Server{
+		Addr:              cmd.Args[0],
+		Handler:           NewServer(*s.Db).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Stop gracefully on Ctrl-C or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	fmt.Printf("Serving the API on %s\n", server.Addr)
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+
+	fmt.Println("Server stopped")
+	return nil
+}
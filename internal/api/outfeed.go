@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/outfeed"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+)
+
+// defaultOutfeedLimit is the number of posts in a user's feed by default
+const defaultOutfeedLimit = 20
+
+// withQueryAPIKey lets a request authenticate with an api_key query
+// parameter when it has no Authorization header, since most feed readers
+// can't send custom headers
+func withQueryAPIKey(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if key := r.URL.Query().Get("api_key"); key != "" && r.Header.Get("Authorization") == "" {
+			r = r.Clone(r.Context())
+			r.Header.Set("Authorization", apiKeyScheme+" "+key)
+		}
+		handler(w, r)
+	}
+}
+
+// handleUserFeed serves GET /users/{id}/feed.xml with the user's posts as
+// one RSS (default) or Atom feed, chosen with ?format=rss|atom
+func (s *Server) handleUserFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if userID != user.ID {
+		respondError(w, http.StatusForbidden, "you can only read your own feed")
+		return
+	}
+
+	query := r.URL.Query()
+	format := outfeed.FormatRSS
+	if value := query.Get("format"); value != "" {
+		format, err = outfeed.ParseFormat(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	limit := defaultOutfeedLimit
+	if value := query.Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPostsLimit {
+			respondError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxPostsLimit))
+			return
+		}
+	}
+
+	rows, err := s.Posts.GetPostsForUser(r.Context(), user.ID, int32(limit))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	feed := posts.NewOutputFeed(user, rows, time.Now())
+	feed.SelfURL = selfURL(r, format)
+
+	var body bytes.Buffer
+	if err := feed.Write(&body, format); err != nil {
+		respondServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	body.WriteTo(w)
+}
+
+// selfURL is the feed's own URL, without the API key so it isn't leaked
+// into the document
+func selfURL(r *http.Request, format outfeed.Format) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	url := scheme + "://" + r.Host + r.URL.Path
+	if format != outfeed.FormatRSS {
+		url += "?format=" + string(format)
+	}
+	return url
+}
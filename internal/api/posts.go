@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+)
+
+// maxPostsLimit is the largest page of posts the API returns
+const maxPostsLimit = 100
+
+// browseQueryFlags maps GET /posts query parameters to browse flags
+var browseQueryFlags = []string{"feed", "since", "until", "sort", "page", "before", "after"}
+
+// postResponse is a post as returned by the API
+type postResponse struct {
+	ID          uuid.UUID           `json:"id"`
+	Title       string              `json:"title"`
+	URL         string              `json:"url"`
+	Description string              `json:"description,omitempty"`
+	Content     string              `json:"content,omitempty"`
+	Authors     []string            `json:"authors"`
+	Categories  []string            `json:"categories"`
+	Enclosures  []enclosureResponse `json:"enclosures"`
+	PublishedAt *time.Time          `json:"published_at"`
+	FeedID      uuid.UUID           `json:"feed_id"`
+	FeedName    string              `json:"feed_name"`
+	Read        bool                `json:"read"`
+	Highlighted bool                `json:"highlighted"`
+}
+
+// enclosureResponse is a media file attached to a post
+type enclosureResponse struct {
+	URL    string `json:"url"`
+	Type   string `json:"type,omitempty"`
+	Length *int64 `json:"length"`
+}
+
+// postsResponse is a page of posts with the cursor for the next page
+type postsResponse struct {
+	Posts []postResponse `json:"posts"`
+	// NextCursor continues after the last post, and is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// parseBrowseQuery turns GET /posts query parameters into browse options.
+// They are converted to browse command arguments so the API and the CLI
+// accept exactly the same filters
+func parseBrowseQuery(r *http.Request, now time.Time) (posts.BrowseOptions, error) {
+	query := r.URL.Query()
+	var args []string
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > maxPostsLimit {
+			return posts.BrowseOptions{}, fmt.Errorf("%w: limit must be between 1 and %d", errBadRequest, maxPostsLimit)
+		}
+		args = append(args, limit)
+	}
+	if unread, _ := strconv.ParseBool(query.Get("unread")); unread {
+		args = append(args, "--unread")
+	}
+	for _, name := range browseQueryFlags {
+		if value := query.Get(name); value != "" {
+			args = append(args, "--"+name, value)
+		}
+	}
+
+	opts, err := posts.ParseBrowseArgs(args, now)
+	if err != nil {
+		return posts.BrowseOptions{}, fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+	return opts, nil
+}
+
+// handleListPosts serves GET /posts with a page of posts from the user's
+// followed feeds, filtered and paged like the browse command
+func (s *Server) handleListPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	opts, err := parseBrowseQuery(r, time.Now())
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	rows, err := s.Posts.BrowsePosts(r.Context(), user.ID, opts)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	enclosures, err := s.Posts.GetEnclosures(r.Context(), posts.PostIDs(rows))
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	filters, err := s.Posts.GetFilters(r.Context(), user.ID)
+	if err != nil {
+		respondServiceError(w, err)
+		return
+	}
+
+	response := postsResponse{Posts: make([]postResponse, 0, len(rows))}
+	for _, row := range rows {
+		post := postResponse{
+			ID:          row.ID,
+			Title:       row.Title,
+			URL:         row.Url,
+			Description: row.Description.String,
+			Content:     row.Content.String,
+			Authors:     append([]string{}, row.Authors...),
+			Categories:  append([]string{}, row.Categories...),
+			Enclosures:  make([]enclosureResponse, 0, len(enclosures[row.ID])),
+			FeedID:      row.FeedID,
+			FeedName:    row.FeedName,
+			Read:        row.IsRead,
+			Highlighted: filters.Match(posts.FilterHighlight, row.FeedID, row.Title, row.Description.String),
+		}
+		if row.PublishedAt.Valid {
+			post.PublishedAt = &row.PublishedAt.Time
+		}
+		for _, enclosure := range enclosures[row.ID] {
+			item := enclosureResponse{URL: enclosure.Url, Type: enclosure.Type.String}
+			if enclosure.Length.Valid {
+				item.Length = &enclosure.Length.Int64
+			}
+			post.Enclosures = append(post.Enclosures, item)
+		}
+		response.Posts = append(response.Posts, post)
+	}
+
+	// A full page means there may be more posts
+	if int32(len(rows)) == opts.Limit {
+		response.NextCursor = posts.NextCursor(rows).String()
+	}
+	respondJSON(w, http.StatusOK, response)
+}
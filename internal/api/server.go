@@ -0,0 +1,130 @@
+// Package api exposes the feed, follow and post services over a JSON REST API
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/feeds"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/abahnj/rssagg/internal/users"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxRequestBodySize bounds how much of a request body is read
+const maxRequestBodySize = 1 << 20
+
+// errBadRequest marks errors caused by invalid client input
+var errBadRequest = errors.New("bad request")
+
+// ErrUnauthorized is returned by an AuthFunc when a request has no valid credentials
+var ErrUnauthorized = errors.New("unauthorized")
+
+// AuthFunc identifies the user making a request
+type AuthFunc func(r *http.Request) (database.User, error)
+
+// Server serves the REST API
+type Server struct {
+	Users *users.Service
+	Feeds *feeds.Service
+	Posts *posts.Service
+	// Authenticate identifies the user behind authenticated endpoints
+	Authenticate AuthFunc
+}
+
+// NewServer creates a server backed by the given database
+func NewServer(db database.Queries) *Server {
+	s := &Server{
+		Users: users.NewService(db),
+		Feeds: feeds.NewService(db),
+		Posts: posts.NewService(db),
+	}
+	s.Authenticate = s.userFromAPIKey
+	return s
+}
+
+// authedHandlerFunc is an endpoint that requires an authenticated user
+type authedHandlerFunc func(http.ResponseWriter, *http.Request, database.User)
+
+// Handler returns the API's routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /feeds", s.handleListFeeds)
+	mux.HandleFunc("POST /feeds", s.requireUser(s.handleCreateFeed))
+	mux.HandleFunc("GET /follows", s.requireUser(s.handleListFollows))
+	mux.HandleFunc("POST /follows", s.requireUser(s.handleFollow))
+	mux.HandleFunc("DELETE /follows", s.requireUser(s.handleUnfollow))
+	mux.HandleFunc("GET /posts", s.requireUser(s.handleListPosts))
+	mux.HandleFunc("GET /users/{id}/feed.xml", withQueryAPIKey(s.requireUser(s.handleUserFeed)))
+	return mux
+}
+
+// requireUser is the server-side equivalent of middleware.MiddlewareLoggedIn
+func (s *Server) requireUser(handler authedHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := s.Authenticate(r)
+		if errors.Is(err, ErrUnauthorized) {
+			w.Header().Set("WWW-Authenticate", apiKeyScheme)
+			respondError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+// errorResponse is the body of every error response
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// respondJSON writes a value as a JSON response
+func respondJSON(w http.ResponseWriter, status int, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Failed to encode response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// respondError writes a JSON error response
+func respondError(w http.ResponseWriter, status int, message string) {
+	respondJSON(w, status, errorResponse{Error: message})
+}
+
+// respondServiceError maps a service error to a status code, hiding the
+// details of unexpected errors from the client
+func respondServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errBadRequest):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, pgx.ErrNoRows):
+		respondError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, feeds.ErrAlreadyFollowing):
+		respondError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("API error: %v", err)
+		respondError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// decodeJSON reads a JSON request body into dst
+func decodeJSON(r *http.Request, dst any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("%w: invalid JSON body: %v", errBadRequest, err)
+	}
+	return nil
+}
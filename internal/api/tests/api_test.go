@@ -0,0 +1,495 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/api"
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/users"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var testUser = database.User{ID: uuid.New(), Name: "alice"}
+
+// newTestServer serves the API from a fake database, authenticating every
+// request that has an X-Test-User header as testUser
+func newTestServer(db *dbtest.DB) *httptest.Server {
+	server := api.NewServer(dbtest.New(db))
+	server.Authenticate = func(r *http.Request) (database.User, error) {
+		if r.Header.Get("X-Test-User") == "" {
+			return database.User{}, api.ErrUnauthorized
+		}
+		return testUser, nil
+	}
+	return httptest.NewServer(server.Handler())
+}
+
+func doRequest(t *testing.T, server *httptest.Server, method, path, body string, authed bool) (*http.Response, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if authed {
+		req.Header.Set("X-Test-User", "alice")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]any
+	json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp, decoded
+}
+
+func timestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: true}
+}
+
+func TestAuthentication(t *testing.T) {
+	server := newTestServer(&dbtest.DB{})
+	defer server.Close()
+
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/feeds"},
+		{"GET", "/follows"},
+		{"POST", "/follows"},
+		{"DELETE", "/follows?url=https://example.com/feed"},
+		{"GET", "/posts"},
+	} {
+		resp, body := doRequest(t, server, route.method, route.path, "", false)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected 401, got %d", route.method, route.path, resp.StatusCode)
+		}
+		if body["error"] == nil {
+			t.Errorf("%s %s: expected an error message", route.method, route.path)
+		}
+	}
+}
+
+func TestListFeeds(t *testing.T) {
+	feedID := uuid.New()
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	db := &dbtest.DB{Rows: map[string][][]any{
+		"GetFeedsWithUsers": {{feedID, "Go Blog", "https://go.dev/blog/feed.atom", testUser.ID, timestamp(created), timestamp(created), "alice"}},
+	}}
+	server := newTestServer(db)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/feeds")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected JSON content type, got %s", contentType)
+	}
+
+	var feeds []map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&feeds); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(feeds) != 1 {
+		t.Fatalf("Expected 1 feed, got %d", len(feeds))
+	}
+	expected := map[string]any{
+		"id":         feedID.String(),
+		"name":       "Go Blog",
+		"url":        "https://go.dev/blog/feed.atom",
+		"added_by":   "alice",
+		"created_at": "2024-01-02T03:04:05Z",
+	}
+	if !reflect.DeepEqual(feeds[0], expected) {
+		t.Errorf("Expected %v, got %v", expected, feeds[0])
+	}
+}
+
+func TestCreateFeedValidation(t *testing.T) {
+	db := &dbtest.DB{}
+	server := newTestServer(db)
+	defer server.Close()
+
+	for name, body := range map[string]string{
+		"Invalid JSON":     `{"name":`,
+		"Unknown field":    `{"name":"Go","url":"https://go.dev/blog/feed.atom","color":"blue"}`,
+		"Missing URL":      `{"name":"Go"}`,
+		"Invalid interval": `{"name":"Go","url":"https://go.dev/blog/feed.atom","fetch_interval":"soon"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp, _ := doRequest(t, server, "POST", "/feeds", body, true)
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", resp.StatusCode)
+			}
+		})
+	}
+
+	if len(db.Queries) != 0 {
+		t.Errorf("Expected invalid requests not to reach the database, got %d queries", len(db.Queries))
+	}
+}
+
+func TestCreateFeed(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Go Blog", Url: "https://go.dev/blog/feed.atom", CreatedAt: timestamp(time.Now())}
+	follow := []any{uuid.New(), testUser.ID, feed.ID, timestamp(time.Now()), timestamp(time.Now()), "alice", feed.Name}
+
+	t.Run("New feed", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"CreateFeed": {dbtest.FeedRow(feed)}, "CreateFeedFollow": {follow}}}
+		// The feed is only found once it has been created
+		db.Answer = func(name string, args []any) [][]any {
+			if name == "GetFeedByURL" && db.Count("CreateFeed") > 0 {
+				return [][]any{dbtest.FeedRow(feed)}
+			}
+			return nil
+		}
+		server := newTestServer(db)
+		defer server.Close()
+
+		resp, _ := doRequest(t, server, "POST", "/feeds", `{"name":"Go Blog","url":"https://go.dev/blog/feed.atom","fetch_interval":"15m"}`, true)
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("Expected 201, got %d", resp.StatusCode)
+		}
+		if db.Count("SetFeedFetchIntervalOverride") != 1 {
+			t.Errorf("Expected the new feed's interval to be set, got queries %v", db.Queries)
+		}
+	})
+
+	t.Run("Existing feed", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"GetFeedByURL": {dbtest.FeedRow(feed)}, "CreateFeedFollow": {follow}}}
+		server := newTestServer(db)
+		defer server.Close()
+
+		// Adding it under the same name still reuses the shared feed
+		resp, body := doRequest(t, server, "POST", "/feeds", `{"name":"Go Blog","url":"https://go.dev/blog/feed.atom","fetch_interval":"1m"}`, true)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+		if body["id"] != feed.ID.String() {
+			t.Errorf("Expected the existing feed, got %v", body)
+		}
+		if db.Count("CreateFeed") != 0 || db.Count("SetFeedFetchIntervalOverride") != 0 {
+			t.Errorf("Expected the shared feed to be left unchanged, got queries %v", db.Queries)
+		}
+		if db.Count("CreateFeedFollow") != 1 {
+			t.Errorf("Expected the user to follow the feed, got queries %v", db.Queries)
+		}
+	})
+}
+
+func TestFollow(t *testing.T) {
+	t.Run("Unknown feed", func(t *testing.T) {
+		server := newTestServer(&dbtest.DB{})
+		defer server.Close()
+
+		resp, _ := doRequest(t, server, "POST", "/follows", `{"url":"https://example.com/missing"}`, true)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Database error is hidden", func(t *testing.T) {
+		server := newTestServer(&dbtest.DB{Err: errors.New("connection refused")})
+		defer server.Close()
+
+		resp, body := doRequest(t, server, "POST", "/follows", `{"url":"https://example.com/feed"}`, true)
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", resp.StatusCode)
+		}
+		if body["error"] != "internal server error" {
+			t.Errorf("Expected a generic error, got %v", body["error"])
+		}
+	})
+
+	t.Run("Unfollow requires a URL", func(t *testing.T) {
+		server := newTestServer(&dbtest.DB{})
+		defer server.Close()
+
+		resp, _ := doRequest(t, server, "DELETE", "/follows", "", true)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Unfollow", func(t *testing.T) {
+		db := &dbtest.DB{}
+		server := newTestServer(db)
+		defer server.Close()
+
+		resp, _ := doRequest(t, server, "DELETE", "/follows?url=https://example.com/feed", "", true)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Expected 204, got %d", resp.StatusCode)
+		}
+		if len(db.Queries) != 1 {
+			t.Errorf("Expected 1 query, got %d", len(db.Queries))
+		}
+	})
+}
+
+func TestListPosts(t *testing.T) {
+	published := time.Date(2024, 1, 31, 8, 15, 0, 0, time.UTC)
+	postID := uuid.New()
+	feedID := uuid.New()
+	row := []any{
+		postID, timestamp(published), timestamp(published), "Hello", "https://example.com/hello",
+		pgtype.Text{String: "First post", Valid: true}, timestamp(published), feedID,
+		pgtype.Text{String: "<p>Full post</p>", Valid: true}, []string{"Alice"}, []string{"news", "go"},
+		"Example", false, timestamp(published),
+	}
+	enclosure := []any{postID, "https://example.com/hello.mp3", pgtype.Text{String: "audio/mpeg", Valid: true}, pgtype.Int8{Int64: 1024, Valid: true}}
+
+	t.Run("Full page includes next cursor", func(t *testing.T) {
+		server := newTestServer(&dbtest.DB{Rows: map[string][][]any{"GetPostsForUser": {row}, "GetEnclosuresForPosts": {enclosure}}})
+		defer server.Close()
+
+		resp, body := doRequest(t, server, "GET", "/posts?limit=1&unread=true", "", true)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+
+		posts, _ := body["posts"].([]any)
+		if len(posts) != 1 {
+			t.Fatalf("Expected 1 post, got %v", body["posts"])
+		}
+		post := posts[0].(map[string]any)
+		if post["id"] != postID.String() || post["title"] != "Hello" || post["read"] != false || post["published_at"] != "2024-01-31T08:15:00Z" {
+			t.Errorf("Unexpected post: %v", post)
+		}
+		if post["content"] != "<p>Full post</p>" || fmt.Sprint(post["authors"]) != "[Alice]" || fmt.Sprint(post["categories"]) != "[news go]" {
+			t.Errorf("Expected content, authors and categories, got %v", post)
+		}
+		enclosures, _ := post["enclosures"].([]any)
+		if len(enclosures) != 1 {
+			t.Fatalf("Expected 1 enclosure, got %v", post["enclosures"])
+		}
+		if got := enclosures[0].(map[string]any); got["url"] != "https://example.com/hello.mp3" || got["type"] != "audio/mpeg" || got["length"] != float64(1024) {
+			t.Errorf("Unexpected enclosure: %v", got)
+		}
+
+		expectedCursor := "2024-01-31T08:15:00," + postID.String()
+		if body["next_cursor"] != expectedCursor {
+			t.Errorf("Expected next cursor %s, got %v", expectedCursor, body["next_cursor"])
+		}
+	})
+
+	t.Run("Partial page has no next cursor", func(t *testing.T) {
+		server := newTestServer(&dbtest.DB{Rows: map[string][][]any{"GetPostsForUser": {row}}})
+		defer server.Close()
+
+		_, body := doRequest(t, server, "GET", "/posts?limit=5", "", true)
+		if _, ok := body["next_cursor"]; ok {
+			t.Errorf("Expected no next cursor, got %v", body["next_cursor"])
+		}
+	})
+
+	t.Run("Empty result is an empty array", func(t *testing.T) {
+		server := newTestServer(&dbtest.DB{})
+		defer server.Close()
+
+		_, body := doRequest(t, server, "GET", "/posts", "", true)
+		if posts, ok := body["posts"].([]any); !ok || len(posts) != 0 {
+			t.Errorf("Expected empty posts array, got %v", body["posts"])
+		}
+	})
+
+	t.Run("Filter rules hide and highlight posts", func(t *testing.T) {
+		hiddenID := uuid.New()
+		hidden := append([]any{hiddenID}, row[1:]...)
+		hidden[3] = "Sponsored: buy now"
+		rules := [][]any{
+			{uuid.New(), testUser.ID, pgtype.UUID{}, pgtype.Text{String: "^Sponsored", Valid: true}, pgtype.Text{}, "hide", timestamp(published), pgtype.Text{}},
+			{uuid.New(), testUser.ID, pgtype.UUID{Bytes: feedID, Valid: true}, pgtype.Text{}, pgtype.Text{String: "first", Valid: true}, "highlight", timestamp(published), pgtype.Text{String: "https://example.com/feed", Valid: true}},
+		}
+		server := newTestServer(&dbtest.DB{Rows: map[string][][]any{"GetPostsForUser": {hidden, row}, "GetFilterRulesForUser": rules}})
+		defer server.Close()
+
+		_, body := doRequest(t, server, "GET", "/posts?limit=5", "", true)
+		posts, _ := body["posts"].([]any)
+		if len(posts) != 1 {
+			t.Fatalf("Expected the sponsored post to be hidden, got %v", body["posts"])
+		}
+		if post := posts[0].(map[string]any); post["id"] != postID.String() || post["highlighted"] != true {
+			t.Errorf("Expected highlighted post %s, got %v", postID, post)
+		}
+	})
+
+	for _, query := range []string{"limit=0", "limit=1000", "since=yesterday", "sort=random", "before=bad", "page=2&after=2024-01-31T08:15:00," + postID.String()} {
+		t.Run("Invalid "+query, func(t *testing.T) {
+			server := newTestServer(&dbtest.DB{})
+			defer server.Close()
+
+			resp, _ := doRequest(t, server, "GET", "/posts?"+query, "", true)
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server := newTestServer(&dbtest.DB{})
+	defer server.Close()
+
+	resp, _ := doRequest(t, server, "PUT", "/feeds", "", true)
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	key, err := users.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %v", err)
+	}
+	created := timestamp(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	userRow := []any{testUser.ID, testUser.Name, created, created, pgtype.Text{String: users.HashToken(key), Valid: true}, pgtype.Text{}}
+
+	db := &dbtest.DB{Rows: map[string][][]any{"GetUserByAPIKeyHash": {userRow}}}
+	server := httptest.NewServer(api.NewServer(dbtest.New(db)).Handler())
+	defer server.Close()
+
+	get := func(authorization string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("GET", server.URL+"/follows", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("Valid key", func(t *testing.T) {
+		db.Args = nil
+		if resp := get("ApiKey " + key); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode)
+		}
+		// The key is looked up by its hash, never in plain text
+		lookup := db.LastArgs("GetUserByAPIKeyHash")
+		if len(lookup) != 1 || lookup[0] != (pgtype.Text{String: users.HashToken(key), Valid: true}) {
+			t.Errorf("Expected lookup by key hash, got %v", lookup)
+		}
+		if follows := db.LastArgs("GetFeedFollowsForUser"); len(follows) != 1 || follows[0] != testUser.ID {
+			t.Errorf("Expected follows for the key's user, got %v", follows)
+		}
+	})
+
+	for name, authorization := range map[string]string{
+		"Missing header": "",
+		"Wrong scheme":   "Bearer " + key,
+		"Unknown key":    "ApiKey rssagg_0000",
+		"Not an API key": "ApiKey hunter2",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if name == "Unknown key" {
+				db.Rows = nil
+				defer func() { db.Rows = map[string][][]any{"GetUserByAPIKeyHash": {userRow}} }()
+			}
+			resp := get(authorization)
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Expected 401, got %d", resp.StatusCode)
+			}
+			if resp.Header.Get("WWW-Authenticate") != "ApiKey" {
+				t.Errorf("Expected WWW-Authenticate: ApiKey, got %q", resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestUserFeed(t *testing.T) {
+	key, err := users.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %v", err)
+	}
+	published := timestamp(time.Date(2024, 1, 31, 8, 15, 0, 0, time.UTC))
+	db := &dbtest.DB{Rows: map[string][][]any{
+		"GetUserByAPIKeyHash": {{testUser.ID, testUser.Name, published, published, pgtype.Text{String: users.HashToken(key), Valid: true}, pgtype.Text{}}},
+		"GetPostsForUser": {{
+			uuid.New(), published, published, "Hello", "https://example.com/hello",
+			pgtype.Text{String: "First post", Valid: true}, published, uuid.New(),
+			pgtype.Text{}, []string{}, []string{}, "Example", false, published,
+		}},
+	}}
+	server := httptest.NewServer(api.NewServer(dbtest.New(db)).Handler())
+	defer server.Close()
+
+	get := func(path string) (*http.Response, string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var body strings.Builder
+		if _, err := io.Copy(&body, resp.Body); err != nil {
+			t.Fatalf("Failed to read body: %v", err)
+		}
+		return resp, body.String()
+	}
+	feedPath := "/users/" + testUser.ID.String() + "/feed.xml"
+
+	t.Run("RSS with API key in query", func(t *testing.T) {
+		resp, body := get(feedPath + "?api_key=" + key)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", resp.StatusCode, body)
+		}
+		if resp.Header.Get("Content-Type") != "application/rss+xml; charset=utf-8" {
+			t.Errorf("Unexpected content type %s", resp.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(body, "<title>Hello</title>") {
+			t.Errorf("Expected post in feed:\n%s", body)
+		}
+		if strings.Contains(body, key) {
+			t.Error("Expected the API key not to appear in the feed")
+		}
+		if !strings.Contains(body, server.URL+feedPath+"</link>") {
+			t.Errorf("Expected self link in feed:\n%s", body)
+		}
+	})
+
+	t.Run("Atom", func(t *testing.T) {
+		resp, body := get(feedPath + "?format=atom&api_key=" + key)
+		if resp.Header.Get("Content-Type") != "application/atom+xml; charset=utf-8" {
+			t.Errorf("Unexpected content type %s", resp.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(body, `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+			t.Errorf("Expected Atom feed:\n%s", body)
+		}
+	})
+
+	t.Run("Without a key", func(t *testing.T) {
+		if resp, _ := get(feedPath); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Another user's feed", func(t *testing.T) {
+		if resp, _ := get("/users/" + uuid.New().String() + "/feed.xml?api_key=" + key); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected 403, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		if resp, _ := get(feedPath + "?format=json&api_key=" + key); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", resp.StatusCode)
+		}
+	})
+}
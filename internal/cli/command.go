@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 )
 
 // CommandName is a custom string type for command names
@@ -40,5 +42,46 @@ func (c *Commands) Run(s *State, cmd Command) error {
 		return fmt.Errorf("unknown command: %s", cmd.Name)
 	}
 
+	// --output is global, so strip it before the handler sees its arguments
+	args, format, err := extractOutputFlag(cmd.Args)
+	if err != nil {
+		return err
+	}
+	cmd.Args = args
+	s.Output = format
+
 	return handler(s, cmd)
+}
+
+// extractOutputFlag removes "--output <format>" or "--output=<format>" from
+// the arguments, returning the selected format (default: table)
+func extractOutputFlag(args []string) ([]string, OutputFormat, error) {
+	format := FormatTable
+	var remaining []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var value string
+		switch {
+		case arg == "--output":
+			if i+1 >= len(args) {
+				return nil, "", errors.New("--output requires a format (json, csv, tsv or table)")
+			}
+			i++
+			value = args[i]
+		case strings.HasPrefix(arg, "--output="):
+			value = strings.TrimPrefix(arg, "--output=")
+		default:
+			remaining = append(remaining, arg)
+			continue
+		}
+
+		parsed, err := ParseOutputFormat(value)
+		if err != nil {
+			return nil, "", err
+		}
+		format = parsed
+	}
+
+	return remaining, format, nil
 }
\ No newline at end of file
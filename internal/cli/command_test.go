@@ -66,4 +66,55 @@ func TestCommands_Run(t *testing.T) {
 			t.Errorf("Expected error %v, got %v", expectedErr, err)
 		}
 	})
+}
+
+func TestCommands_RunOutputFlag(t *testing.T) {
+	cmds := NewCommands()
+	state := &State{}
+	
+	var gotArgs []string
+	cmds.Register("list", func(_ *State, cmd Command) error {
+		gotArgs = cmd.Args
+		return nil
+	})
+	
+	t.Run("Flag is stripped from arguments", func(t *testing.T) {
+		err := cmds.Run(state, Command{Name: "list", Args: []string{"20", "--output", "json", "--unread"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if state.Output != FormatJSON {
+			t.Errorf("Expected output format json, got %s", state.Output)
+		}
+		if len(gotArgs) != 2 || gotArgs[0] != "20" || gotArgs[1] != "--unread" {
+			t.Errorf("Unexpected arguments: %v", gotArgs)
+		}
+	})
+	
+	t.Run("Equals form", func(t *testing.T) {
+		if err := cmds.Run(state, Command{Name: "list", Args: []string{"--output=csv"}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if state.Output != FormatCSV || len(gotArgs) != 0 {
+			t.Errorf("Expected csv with no arguments, got %s %v", state.Output, gotArgs)
+		}
+	})
+	
+	t.Run("Defaults to table", func(t *testing.T) {
+		if err := cmds.Run(state, Command{Name: "list"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if state.Output != FormatTable {
+			t.Errorf("Expected table output, got %s", state.Output)
+		}
+	})
+	
+	t.Run("Invalid format", func(t *testing.T) {
+		if err := cmds.Run(state, Command{Name: "list", Args: []string{"--output", "xml"}}); err == nil {
+			t.Error("Expected error for invalid format")
+		}
+		if err := cmds.Run(state, Command{Name: "list", Args: []string{"--output"}}); err == nil {
+			t.Error("Expected error for missing format")
+		}
+	})
 }
\ No newline at end of file
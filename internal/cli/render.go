@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/sanitize"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/term"
+)
+
+// OutputFormat is the format listing commands render their rows in
+type OutputFormat string
+
+const (
+	FormatTable OutputFormat = "table"
+	FormatJSON  OutputFormat = "json"
+	FormatCSV   OutputFormat = "csv"
+	FormatTSV   OutputFormat = "tsv"
+)
+
+// defaultTextWidth and maxTextWidth bound the width text is wrapped at:
+// the default is used when standard output isn't a terminal, and wider
+// terminals still wrap at the maximum to keep lines readable
+const (
+	defaultTextWidth = 80
+	maxTextWidth     = 100
+)
+
+// maxCellWidth is the number of characters a table cell shows before it is cut off
+const maxCellWidth = 60
+
+// timeLayout is how times are shown in table, CSV and TSV output
+const timeLayout = "2006-01-02 15:04:05"
+
+// tsvEscaper replaces the characters TSV cannot represent inside a value
+var tsvEscaper = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// ParseOutputFormat validates an --output value
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch format := OutputFormat(strings.ToLower(value)); format {
+	case FormatTable, FormatJSON, FormatCSV, FormatTSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q (expected json, csv, tsv or table)", value)
+	}
+}
+
+// Table holds the structured rows a listing command produces
+type Table struct {
+	// Columns are the snake_case column names, used as JSON keys and CSV headers
+	Columns []string
+	Rows    [][]any
+	// Empty is shown instead of a table when there are no rows
+	Empty string
+}
+
+// NewTable creates a table with the given columns
+func NewTable(columns ...string) *Table {
+	return &Table{Columns: columns}
+}
+
+// AddRow appends a row; values are given in column order
+func (t *Table) AddRow(values ...any) {
+	t.Rows = append(t.Rows, values)
+}
+
+// NullableTime returns the time of a valid timestamp, or nil for NULL
+func NullableTime(ts pgtype.Timestamp) any {
+	if !ts.Valid {
+		return nil
+	}
+	return ts.Time
+}
+
+// Render writes a table to w in the given format
+func Render(w io.Writer, format OutputFormat, table *Table) error {
+	switch format {
+	case FormatJSON:
+		return renderJSON(w, table)
+	case FormatCSV:
+		return renderCSV(w, table)
+	case FormatTSV:
+		return renderTSV(w, table)
+	default:
+		return renderTable(w, table)
+	}
+}
+
+// renderJSON writes the rows as an array of objects, keeping column order
+func renderJSON(w io.Writer, table *Table) error {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, row := range table.Rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		for j, column := range table.Columns {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(column)
+			value, err := json.Marshal(row[j])
+			if err != nil {
+				return fmt.Errorf("failed to encode column %s: %w", column, err)
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return fmt.Errorf("failed to format JSON: %w", err)
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(w)
+	return err
+}
+
+// renderCSV writes the rows as RFC 4180 CSV with a header line
+func renderCSV(w io.Writer, table *Table) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(table.Columns); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = formatValue(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// renderTSV writes the rows tab-separated, replacing tabs and newlines in
+// values with spaces since TSV has no quoting
+func renderTSV(w io.Writer, table *Table) error {
+	lines := []string{strings.Join(table.Columns, "\t")}
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = tsvEscaper.Replace(formatValue(value))
+		}
+		lines = append(lines, strings.Join(record, "\t"))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// renderTable writes the rows as aligned columns for reading in a terminal
+func renderTable(w io.Writer, table *Table) error {
+	if len(table.Rows) == 0 && table.Empty != "" {
+		_, err := fmt.Fprintln(w, table.Empty)
+		return err
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		headers[i] = strings.ToUpper(strings.ReplaceAll(column, "_", " "))
+	}
+	fmt.Fprintln(writer, strings.Join(headers, "\t"))
+
+	for _, row := range table.Rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = truncateCell(strings.Join(strings.Fields(formatValue(value)), " "))
+		}
+		fmt.Fprintln(writer, strings.Join(cells, "\t"))
+	}
+	return writer.Flush()
+}
+
+// formatValue converts a cell value to text
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(timeLayout)
+	case []string:
+		return strings.Join(v, "; ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// truncateCell shortens a cell to maxCellWidth characters, preferably
+// between words
+func truncateCell(value string) string {
+	return sanitize.Truncate(value, maxCellWidth)
+}
+
+// TextWidth returns the width to wrap text at when printing to the terminal
+func TextWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return defaultTextWidth
+	}
+	return min(width, maxTextWidth)
+}
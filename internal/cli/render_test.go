@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleTable() *Table {
+	table := NewTable("name", "url", "created_at", "current")
+	table.AddRow("Go Blog", "https://go.dev/blog/feed.atom", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), true)
+	table.AddRow("Tabs,\tand \"quotes\"", "https://example.com/feed", nil, false)
+	return table
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, value := range []string{"json", "CSV", "tsv", "table"} {
+		if _, err := ParseOutputFormat(value); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", value, err)
+		}
+	}
+	if _, err := ParseOutputFormat("yaml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Run("JSON keeps column order and types", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatJSON, sampleTable()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		output := buf.String()
+		if !strings.Contains(output, `"name": "Go Blog",
+    "url": "https://go.dev/blog/feed.atom",
+    "created_at": "2024-01-02T03:04:05Z",
+    "current": true`) {
+			t.Errorf("Unexpected JSON output:\n%s", output)
+		}
+		if !strings.Contains(output, `"created_at": null`) {
+			t.Errorf("Expected missing time to be null:\n%s", output)
+		}
+	})
+
+	t.Run("JSON with no rows is an empty array", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatJSON, NewTable("name")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if buf.String() != "[]\n" {
+			t.Errorf("Expected empty array, got %q", buf.String())
+		}
+	})
+
+	t.Run("CSV quotes values", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatCSV, sampleTable()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := "name,url,created_at,current\n" +
+			"Go Blog,https://go.dev/blog/feed.atom,2024-01-02 03:04:05,true\n" +
+			"\"Tabs,\tand \"\"quotes\"\"\",https://example.com/feed,,false\n"
+		if buf.String() != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+		}
+	})
+
+	t.Run("TSV replaces tabs in values", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatTSV, sampleTable()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Expected 3 lines, got %d", len(lines))
+		}
+		if lines[2] != "Tabs, and \"quotes\"\thttps://example.com/feed\t\tfalse" {
+			t.Errorf("Unexpected TSV row: %q", lines[2])
+		}
+	})
+
+	t.Run("Table aligns columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatTable, sampleTable()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(buf.String(), "\n")
+		if !strings.HasPrefix(lines[0], "NAME") || !strings.Contains(lines[0], "CREATED AT") {
+			t.Errorf("Unexpected header: %q", lines[0])
+		}
+		if strings.Index(lines[1], "https://") != strings.Index(lines[2], "https://") {
+			t.Errorf("Expected URL column to be aligned:\n%s", buf.String())
+		}
+	})
+
+	t.Run("Table shows empty message", func(t *testing.T) {
+		table := NewTable("name")
+		table.Empty = "No feeds found"
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatTable, table); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if buf.String() != "No feeds found\n" {
+			t.Errorf("Expected empty message, got %q", buf.String())
+		}
+	})
+}
+
+func TestTruncateCell(t *testing.T) {
+	value := strings.Repeat("é", maxCellWidth+10)
+	truncated := truncateCell(value)
+	if len([]rune(truncated)) != maxCellWidth || !strings.HasSuffix(truncated, "...") {
+		t.Errorf("Expected %d characters ending in ..., got %q", maxCellWidth, truncated)
+	}
+}
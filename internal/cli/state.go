@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"io"
+	"os"
+
 	"github.com/abahnj/rssagg/internal/config"
 	"github.com/abahnj/rssagg/internal/database"
 )
@@ -9,4 +12,21 @@ import (
 type State struct {
 	Config *config.Config
 	Db 	*database.Queries
+	// Output is the format listing commands render in, set by --output
+	Output OutputFormat
+	// Out is where listing commands render to (default: standard output)
+	Out io.Writer
+}
+
+// Writer returns where listing commands render to
+func (s *State) Writer() io.Writer {
+	if s.Out == nil {
+		return os.Stdout
+	}
+	return s.Out
+}
+
+// Render writes a listing command's rows in the selected output format
+func (s *State) Render(table *Table) error {
+	return Render(s.Writer(), s.Output, table)
 }
\ No newline at end of file
@@ -13,6 +13,12 @@ const configFileName = "config.json"
 type Config struct {
 	DBURL           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	// SessionToken proves the current user logged in; it's checked against
+	// the sessions table by commands that require a logged-in user
+	SessionToken string `json:"session_token,omitempty"`
+	// MaxFeedFailures is the number of consecutive fetch failures after
+	// which agg disables a feed (0 uses the default)
+	MaxFeedFailures int `json:"max_feed_failures,omitempty"`
 }
 
 // Read loads the configuration from the config file
@@ -42,6 +48,14 @@ func (c *Config) SetUser(username string) error {
 	return write(*c)
 }
 
+// SetSession updates the current user name and session token in the config
+func (c *Config) SetSession(username, token string) error {
+	c.CurrentUserName = username
+	c.SessionToken = token
+
+	return write(*c)
+}
+
 // GetConfigFilePathFunc is the function type for getting config file path
 type GetConfigFilePathFunc func() (string, error)
 
@@ -72,7 +86,27 @@ func write(cfg Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	// The config holds a session token, so keep it private to the user.
+	// os.WriteFile only sets permissions on new files, so write a new 0600
+	// file and rename it over the old one, which may be world-readable
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+configFileName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -115,6 +115,71 @@ func TestConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("SetSession", func(t *testing.T) {
+		// Start from a file that doesn't exist yet so its permissions are new
+		if err := os.Remove(testConfigPath); err != nil && !os.IsNotExist(err) {
+			t.Fatalf("Failed to remove test config: %v", err)
+		}
+
+		cfg := Config{DBURL: "postgres://test"}
+		if err := cfg.SetSession("alice", "token123"); err != nil {
+			t.Fatalf("Failed to set session: %v", err)
+		}
+
+		updatedCfg, err := Read()
+		if err != nil {
+			t.Fatalf("Failed to read updated config: %v", err)
+		}
+
+		if updatedCfg.CurrentUserName != "alice" || updatedCfg.SessionToken != "token123" {
+			t.Errorf("Expected alice with token123, got %q with %q", updatedCfg.CurrentUserName, updatedCfg.SessionToken)
+		}
+
+		// The session token must not be readable by other users
+		info, err := os.Stat(testConfigPath)
+		if err != nil {
+			t.Fatalf("Failed to stat config: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Expected config permissions 0600, got %o", perm)
+		}
+	})
+
+	t.Run("SetSession makes an existing config private", func(t *testing.T) {
+		// Configs written before sessions existed may be world-readable
+		if err := os.WriteFile(testConfigPath, []byte(`{"db_url":"postgres://test"}`), 0644); err != nil {
+			t.Fatalf("Failed to write test config: %v", err)
+		}
+		if err := os.Chmod(testConfigPath, 0644); err != nil {
+			t.Fatalf("Failed to chmod test config: %v", err)
+		}
+
+		cfg, err := Read()
+		if err != nil {
+			t.Fatalf("Failed to read config: %v", err)
+		}
+		if err := cfg.SetSession("alice", "token123"); err != nil {
+			t.Fatalf("Failed to set session: %v", err)
+		}
+
+		info, err := os.Stat(testConfigPath)
+		if err != nil {
+			t.Fatalf("Failed to stat config: %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Expected config permissions 0600, got %o", perm)
+		}
+
+		// No temporary files are left behind
+		entries, err := os.ReadDir(tempDir)
+		if err != nil {
+			t.Fatalf("Failed to read temp dir: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("Expected only the config file, got %d entries", len(entries))
+		}
+	})
+
 	t.Run("JSON Marshal/Unmarshal", func(t *testing.T) {
 		cfg := Config{
 			DBURL:          "postgres://test",
@@ -133,3 +133,22 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const moveFeedFollows = `-- name: MoveFeedFollows :exec
+UPDATE feed_follows
+SET feed_id = $1
+WHERE feed_id = $2
+  AND user_id NOT IN (
+    SELECT user_id FROM feed_follows WHERE feed_id = $1
+  )
+`
+
+type MoveFeedFollowsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MoveFeedFollows(ctx context.Context, arg MoveFeedFollowsParams) error {
+	_, err := q.db.Exec(ctx, moveFeedFollows, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
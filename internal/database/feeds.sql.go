@@ -12,6 +12,59 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET last_fetched_at = NOW(), next_fetch_at = NOW() + fetch_interval, updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    ORDER BY next_fetch_at NULLS FIRST, last_fetched_at NULLS FIRST, updated_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, name, url, user_id, created_at, updated_at, last_fetched_at, etag, last_modified, fetch_interval, fetch_interval_override, next_fetch_at, consecutive_failures, last_error, last_success_at, disabled_at, fetch_full_article, publisher_min_interval, skip_hours
+`
+
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, limit int32) ([]Feed, error) {
+	rows, err := q.db.Query(ctx, claimFeedsToFetch, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchInterval,
+			&i.FetchIntervalOverride,
+			&i.NextFetchAt,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.FetchFullArticle,
+			&i.PublisherMinInterval,
+			&i.SkipHours,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, name, url, user_id)
 VALUES (
@@ -20,7 +73,7 @@ VALUES (
     $3,
     $4
 )
-RETURNING id, name, url, user_id, created_at, updated_at, last_fetched_at
+RETURNING id, name, url, user_id, created_at, updated_at, last_fetched_at, etag, last_modified, fetch_interval, fetch_interval_override, next_fetch_at, consecutive_failures, last_error, last_success_at, disabled_at, fetch_full_article, publisher_min_interval, skip_hours
 `
 
 type CreateFeedParams struct {
@@ -46,12 +99,44 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.FetchIntervalOverride,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.FetchFullArticle,
+		&i.PublisherMinInterval,
+		&i.SkipHours,
 	)
 	return i, err
 }
 
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteFeed, id)
+	return err
+}
+
+const enableFeed = `-- name: EnableFeed :exec
+UPDATE feeds
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, enableFeed, id)
+	return err
+}
+
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, name, url, user_id, created_at, updated_at, last_fetched_at FROM feeds WHERE url = $1 LIMIT 1
+SELECT id, name, url, user_id, created_at, updated_at, last_fetched_at, etag, last_modified, fetch_interval, fetch_interval_override, next_fetch_at, consecutive_failures, last_error, last_success_at, disabled_at, fetch_full_article, publisher_min_interval, skip_hours FROM feeds WHERE url = $1 LIMIT 1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -65,12 +150,24 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.FetchIntervalOverride,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.FetchFullArticle,
+		&i.PublisherMinInterval,
+		&i.SkipHours,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, name, url, user_id, created_at, updated_at, last_fetched_at FROM feeds ORDER BY created_at DESC
+SELECT id, name, url, user_id, created_at, updated_at, last_fetched_at, etag, last_modified, fetch_interval, fetch_interval_override, next_fetch_at, consecutive_failures, last_error, last_success_at, disabled_at, fetch_full_article, publisher_min_interval, skip_hours FROM feeds ORDER BY created_at DESC
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -90,6 +187,18 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchInterval,
+			&i.FetchIntervalOverride,
+			&i.NextFetchAt,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.FetchFullArticle,
+			&i.PublisherMinInterval,
+			&i.SkipHours,
 		); err != nil {
 			return nil, err
 		}
@@ -153,14 +262,90 @@ func (q *Queries) GetFeedsWithUsers(ctx context.Context) ([]GetFeedsWithUsersRow
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, name, url, user_id, created_at, updated_at, last_fetched_at FROM feeds
-ORDER BY last_fetched_at NULLS FIRST, updated_at
-LIMIT 1
+const getUnhealthyFeeds = `-- name: GetUnhealthyFeeds :many
+SELECT id, name, url, user_id, created_at, updated_at, last_fetched_at, etag, last_modified, fetch_interval, fetch_interval_override, next_fetch_at, consecutive_failures, last_error, last_success_at, disabled_at, fetch_full_article, publisher_min_interval, skip_hours FROM feeds
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY disabled_at NULLS LAST, consecutive_failures DESC, name
+`
+
+func (q *Queries) GetUnhealthyFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.Query(ctx, getUnhealthyFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchInterval,
+			&i.FetchIntervalOverride,
+			&i.NextFetchAt,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.FetchFullArticle,
+			&i.PublisherMinInterval,
+			&i.SkipHours,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFeedFetched = `-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), last_success_at = NOW(), consecutive_failures = 0, last_error = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markFeedFetched, id)
+	return err
+}
+
+const recordFeedFailure = `-- name: RecordFeedFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+    last_error = $1,
+    next_fetch_at = NOW() + $2::interval,
+    disabled_at = CASE
+        WHEN consecutive_failures + 1 >= $3::integer THEN NOW()
+        ELSE disabled_at
+    END
+WHERE id = $4
+RETURNING id, name, url, user_id, created_at, updated_at, last_fetched_at, etag, last_modified, fetch_interval, fetch_interval_override, next_fetch_at, consecutive_failures, last_error, last_success_at, disabled_at, fetch_full_article, publisher_min_interval, skip_hours
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRow(ctx, getNextFeedToFetch)
+type RecordFeedFailureParams struct {
+	LastError   pgtype.Text
+	Delay       pgtype.Interval
+	MaxFailures int32
+	ID          uuid.UUID
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (Feed, error) {
+	row := q.db.QueryRow(ctx, recordFeedFailure,
+		arg.LastError,
+		arg.Delay,
+		arg.MaxFailures,
+		arg.ID,
+	)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -170,17 +355,138 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.FetchIntervalOverride,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.FetchFullArticle,
+		&i.PublisherMinInterval,
+		&i.SkipHours,
 	)
 	return i, err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
+const scheduleFeedFetch = `-- name: ScheduleFeedFetch :exec
 UPDATE feeds
-SET last_fetched_at = NOW(), updated_at = NOW()
+SET fetch_interval = $1, next_fetch_at = NOW() + $2::interval,
+    publisher_min_interval = $3, skip_hours = $4
+WHERE id = $5
+`
+
+type ScheduleFeedFetchParams struct {
+	FetchInterval        pgtype.Interval
+	Delay                pgtype.Interval
+	PublisherMinInterval pgtype.Interval
+	SkipHours            []int32
+	ID                   uuid.UUID
+}
+
+func (q *Queries) ScheduleFeedFetch(ctx context.Context, arg ScheduleFeedFetchParams) error {
+	_, err := q.db.Exec(ctx, scheduleFeedFetch,
+		arg.FetchInterval,
+		arg.Delay,
+		arg.PublisherMinInterval,
+		arg.SkipHours,
+		arg.ID,
+	)
+	return err
+}
+
+const setFeedFetchFullArticle = `-- name: SetFeedFetchFullArticle :execrows
+UPDATE feeds
+SET fetch_full_article = $2, updated_at = NOW()
+WHERE id = $1 AND EXISTS (
+    SELECT 1 FROM feed_follows ff WHERE ff.feed_id = feeds.id AND ff.user_id = $3
+)
+`
+
+type SetFeedFetchFullArticleParams struct {
+	ID               uuid.UUID
+	FetchFullArticle bool
+	UserID           uuid.UUID
+}
+
+func (q *Queries) SetFeedFetchFullArticle(ctx context.Context, arg SetFeedFetchFullArticleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setFeedFetchFullArticle, arg.ID, arg.FetchFullArticle, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setFeedFetchIntervalOverride = `-- name: SetFeedFetchIntervalOverride :exec
+UPDATE feeds
+SET fetch_interval_override = $2, fetch_interval = COALESCE($2, fetch_interval), next_fetch_at = NULL
 WHERE id = $1
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, markFeedFetched, id)
+type SetFeedFetchIntervalOverrideParams struct {
+	ID                    uuid.UUID
+	FetchIntervalOverride pgtype.Interval
+}
+
+func (q *Queries) SetFeedFetchIntervalOverride(ctx context.Context, arg SetFeedFetchIntervalOverrideParams) error {
+	_, err := q.db.Exec(ctx, setFeedFetchIntervalOverride, arg.ID, arg.FetchIntervalOverride)
 	return err
 }
+
+const updateFeedCacheValidators = `-- name: UpdateFeedCacheValidators :exec
+UPDATE feeds
+SET etag = $2, last_modified = $3
+WHERE id = $1
+`
+
+type UpdateFeedCacheValidatorsParams struct {
+	ID           uuid.UUID
+	Etag         pgtype.Text
+	LastModified pgtype.Text
+}
+
+func (q *Queries) UpdateFeedCacheValidators(ctx context.Context, arg UpdateFeedCacheValidatorsParams) error {
+	_, err := q.db.Exec(ctx, updateFeedCacheValidators, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :one
+UPDATE feeds
+SET url = $2
+WHERE id = $1
+RETURNING id, name, url, user_id, created_at, updated_at, last_fetched_at, etag, last_modified, fetch_interval, fetch_interval_override, next_fetch_at, consecutive_failures, last_error, last_success_at, disabled_at, fetch_full_article, publisher_min_interval, skip_hours
+`
+
+type UpdateFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) (Feed, error) {
+	row := q.db.QueryRow(ctx, updateFeedURL, arg.ID, arg.Url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchInterval,
+		&i.FetchIntervalOverride,
+		&i.NextFetchAt,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.FetchFullArticle,
+		&i.PublisherMinInterval,
+		&i.SkipHours,
+	)
+	return i, err
+}
@@ -10,13 +10,32 @@ import (
 )
 
 type Feed struct {
-	ID            uuid.UUID
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	CreatedAt     pgtype.Timestamp
-	UpdatedAt     pgtype.Timestamp
-	LastFetchedAt pgtype.Timestamp
+	ID                    uuid.UUID
+	Name                  string
+	Url                   string
+	UserID                uuid.UUID
+	CreatedAt             pgtype.Timestamp
+	UpdatedAt             pgtype.Timestamp
+	LastFetchedAt         pgtype.Timestamp
+	Etag                  pgtype.Text
+	LastModified          pgtype.Text
+	FetchInterval         pgtype.Interval
+	FetchIntervalOverride pgtype.Interval
+	NextFetchAt           pgtype.Timestamp
+	ConsecutiveFailures   int32
+	LastError             pgtype.Text
+	LastSuccessAt         pgtype.Timestamp
+	DisabledAt            pgtype.Timestamp
+	FetchFullArticle      bool
+	PublisherMinInterval  pgtype.Interval
+	SkipHours             []int32
+}
+
+type FeedError struct {
+	ID        uuid.UUID
+	FeedID    uuid.UUID
+	Error     string
+	CreatedAt pgtype.Timestamp
 }
 
 type FeedFollow struct {
@@ -27,20 +46,73 @@ type FeedFollow struct {
 	UpdatedAt pgtype.Timestamp
 }
 
+type FilterRule struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	FeedID     pgtype.UUID
+	TitleRegex pgtype.Text
+	Keyword    pgtype.Text
+	Action     string
+	CreatedAt  pgtype.Timestamp
+}
+
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   pgtype.Timestamp
-	UpdatedAt   pgtype.Timestamp
+	ID              uuid.UUID
+	CreatedAt       pgtype.Timestamp
+	UpdatedAt       pgtype.Timestamp
+	Title           string
+	Url             string
+	Description     pgtype.Text
+	PublishedAt     pgtype.Timestamp
+	FeedID          uuid.UUID
+	Guid            pgtype.Text
+	CanonicalUrl    string
+	DuplicateOf     pgtype.UUID
+	Content         pgtype.Text
+	Authors         []string
+	Categories      []string
+	DurationSeconds pgtype.Int4
+	Episode         pgtype.Int4
+	ImageUrl        pgtype.Text
+	Article         pgtype.Text
+}
+
+type PostEnclosure struct {
+	PostID uuid.UUID
+	Url    string
+	Type   pgtype.Text
+	Length pgtype.Int8
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt pgtype.Timestamp
+}
+
+type Session struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt pgtype.Timestamp
+	ExpiresAt pgtype.Timestamp
+}
+
+type StarredPost struct {
+	UserID      uuid.UUID
+	PostID      uuid.UUID
 	Title       string
 	Url         string
 	Description pgtype.Text
 	PublishedAt pgtype.Timestamp
-	FeedID      uuid.UUID
+	FeedName    string
+	StarredAt   pgtype.Timestamp
 }
 
 type User struct {
-	ID        uuid.UUID
-	Name      string
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+	ID           uuid.UUID
+	Name         string
+	CreatedAt    pgtype.Timestamp
+	UpdatedAt    pgtype.Timestamp
+	ApiKeyHash   pgtype.Text
+	PasswordHash pgtype.Text
 }
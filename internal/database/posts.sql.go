@@ -13,18 +13,38 @@ import (
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (
+    id, title, url, description, published_at, feed_id, guid, canonical_url, content, authors, categories,
+    duration_seconds, episode, image_url, duplicate_of
+)
+VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+    (
+        SELECT o.id FROM posts o
+        WHERE o.canonical_url = $8 AND o.feed_id <> $6 AND o.duplicate_of IS NULL
+        ORDER BY o.created_at, o.id
+        LIMIT 1
+    )
+)
+ON CONFLICT DO NOTHING
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, guid, canonical_url, duplicate_of, content, authors, categories, duration_seconds, episode, image_url, article
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	Title       string
-	Url         string
-	Description pgtype.Text
-	PublishedAt pgtype.Timestamp
-	FeedID      uuid.UUID
+	ID              uuid.UUID
+	Title           string
+	Url             string
+	Description     pgtype.Text
+	PublishedAt     pgtype.Timestamp
+	FeedID          uuid.UUID
+	Guid            pgtype.Text
+	CanonicalUrl    string
+	Content         pgtype.Text
+	Authors         []string
+	Categories      []string
+	DurationSeconds pgtype.Int4
+	Episode         pgtype.Int4
+	ImageUrl        pgtype.Text
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -35,6 +55,14 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Guid,
+		arg.CanonicalUrl,
+		arg.Content,
+		arg.Authors,
+		arg.Categories,
+		arg.DurationSeconds,
+		arg.Episode,
+		arg.ImageUrl,
 	)
 	var i Post
 	err := row.Scan(
@@ -46,6 +74,64 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Guid,
+		&i.CanonicalUrl,
+		&i.DuplicateOf,
+		&i.Content,
+		&i.Authors,
+		&i.Categories,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.ImageUrl,
+		&i.Article,
+	)
+	return i, err
+}
+
+const deleteMovedPostDuplicates = `-- name: DeleteMovedPostDuplicates :exec
+DELETE FROM posts p
+USING posts t
+WHERE p.feed_id = $1
+  AND t.feed_id = $2
+  AND (p.canonical_url = t.canonical_url OR p.guid = t.guid)
+`
+
+type DeleteMovedPostDuplicatesParams struct {
+	FromFeedID uuid.UUID
+	ToFeedID   uuid.UUID
+}
+
+func (q *Queries) DeleteMovedPostDuplicates(ctx context.Context, arg DeleteMovedPostDuplicatesParams) error {
+	_, err := q.db.Exec(ctx, deleteMovedPostDuplicates, arg.FromFeedID, arg.ToFeedID)
+	return err
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, canonical_url, duplicate_of, content, authors, categories, duration_seconds, episode, image_url, article FROM posts WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRow(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.CanonicalUrl,
+		&i.DuplicateOf,
+		&i.Content,
+		&i.Authors,
+		&i.Categories,
+		&i.DurationSeconds,
+		&i.Episode,
+		&i.ImageUrl,
+		&i.Article,
 	)
 	return i, err
 }
@@ -60,18 +146,46 @@ SELECT
     p.description,
     p.published_at,
     p.feed_id,
-    f.name AS feed_name
+    p.content,
+    p.authors,
+    p.categories,
+    f.name AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+    ) AS is_read,
+    COALESCE(p.published_at, p.created_at)::timestamp AS sort_at
 FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN feed_follows ff ON f.id = ff.feed_id
 WHERE ff.user_id = $1
-ORDER BY p.published_at DESC
-LIMIT $2
+  AND (NOT $2::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+  ))
+  AND ($3::text IS NULL OR f.url = $3 OR lower(f.name) = lower($3))
+  AND (p.duplicate_of IS NULL OR $3::text IS NOT NULL OR NOT EXISTS (
+    SELECT 1 FROM posts o
+    JOIN feed_follows off ON o.feed_id = off.feed_id
+    WHERE o.id = p.duplicate_of AND off.user_id = ff.user_id
+  ))
+  AND ($4::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) >= $4)
+  AND ($5::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < $5)
+  AND ($6::timestamp IS NULL
+    OR (COALESCE(p.published_at, p.created_at), p.id) < ($6, $7::uuid))
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT $8
+OFFSET $9
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID     uuid.UUID
+	UnreadOnly bool
+	Feed       pgtype.Text
+	Since      pgtype.Timestamp
+	Until      pgtype.Timestamp
+	CursorAt   pgtype.Timestamp
+	CursorID   uuid.UUID
+	PostLimit  int32
+	PostOffset int32
 }
 
 type GetPostsForUserRow struct {
@@ -83,11 +197,26 @@ type GetPostsForUserRow struct {
 	Description pgtype.Text
 	PublishedAt pgtype.Timestamp
 	FeedID      uuid.UUID
+	Content     pgtype.Text
+	Authors     []string
+	Categories  []string
 	FeedName    string
+	IsRead      bool
+	SortAt      pgtype.Timestamp
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.Query(ctx, getPostsForUser, arg.UserID, arg.Limit)
+	rows, err := q.db.Query(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.Feed,
+		arg.Since,
+		arg.Until,
+		arg.CursorAt,
+		arg.CursorID,
+		arg.PostLimit,
+		arg.PostOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +233,12 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Content,
+			&i.Authors,
+			&i.Categories,
 			&i.FeedName,
+			&i.IsRead,
+			&i.SortAt,
 		); err != nil {
 			return nil, err
 		}
@@ -115,3 +249,270 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getPostsForUserOldestFirst = `-- name: GetPostsForUserOldestFirst :many
+SELECT 
+    p.id,
+    p.created_at,
+    p.updated_at,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.content,
+    p.authors,
+    p.categories,
+    f.name AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+    ) AS is_read,
+    COALESCE(p.published_at, p.created_at)::timestamp AS sort_at
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = $1
+  AND (NOT $2::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+  ))
+  AND ($3::text IS NULL OR f.url = $3 OR lower(f.name) = lower($3))
+  AND (p.duplicate_of IS NULL OR $3::text IS NOT NULL OR NOT EXISTS (
+    SELECT 1 FROM posts o
+    JOIN feed_follows off ON o.feed_id = off.feed_id
+    WHERE o.id = p.duplicate_of AND off.user_id = ff.user_id
+  ))
+  AND ($4::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) >= $4)
+  AND ($5::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < $5)
+  AND ($6::timestamp IS NULL
+    OR (COALESCE(p.published_at, p.created_at), p.id) > ($6, $7::uuid))
+ORDER BY COALESCE(p.published_at, p.created_at) ASC, p.id ASC
+LIMIT $8
+OFFSET $9
+`
+
+type GetPostsForUserOldestFirstParams struct {
+	UserID     uuid.UUID
+	UnreadOnly bool
+	Feed       pgtype.Text
+	Since      pgtype.Timestamp
+	Until      pgtype.Timestamp
+	CursorAt   pgtype.Timestamp
+	CursorID   uuid.UUID
+	PostLimit  int32
+	PostOffset int32
+}
+
+type GetPostsForUserOldestFirstRow struct {
+	ID          uuid.UUID
+	CreatedAt   pgtype.Timestamp
+	UpdatedAt   pgtype.Timestamp
+	Title       string
+	Url         string
+	Description pgtype.Text
+	PublishedAt pgtype.Timestamp
+	FeedID      uuid.UUID
+	Content     pgtype.Text
+	Authors     []string
+	Categories  []string
+	FeedName    string
+	IsRead      bool
+	SortAt      pgtype.Timestamp
+}
+
+func (q *Queries) GetPostsForUserOldestFirst(ctx context.Context, arg GetPostsForUserOldestFirstParams) ([]GetPostsForUserOldestFirstRow, error) {
+	rows, err := q.db.Query(ctx, getPostsForUserOldestFirst,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.Feed,
+		arg.Since,
+		arg.Until,
+		arg.CursorAt,
+		arg.CursorID,
+		arg.PostLimit,
+		arg.PostOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserOldestFirstRow
+	for rows.Next() {
+		var i GetPostsForUserOldestFirstRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Content,
+			&i.Authors,
+			&i.Categories,
+			&i.FeedName,
+			&i.IsRead,
+			&i.SortAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsMissingArticle = `-- name: GetPostsMissingArticle :many
+SELECT id, url FROM posts
+WHERE feed_id = $1 AND article IS NULL AND created_at > NOW() - INTERVAL '1 day'
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type GetPostsMissingArticleParams struct {
+	FeedID uuid.UUID
+	Limit  int32
+}
+
+type GetPostsMissingArticleRow struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) GetPostsMissingArticle(ctx context.Context, arg GetPostsMissingArticleParams) ([]GetPostsMissingArticleRow, error) {
+	rows, err := q.db.Query(ctx, getPostsMissingArticle, arg.FeedID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsMissingArticleRow
+	for rows.Next() {
+		var i GetPostsMissingArticleRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const movePosts = `-- name: MovePosts :exec
+UPDATE posts
+SET feed_id = $1
+WHERE feed_id = $2
+`
+
+type MovePostsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MovePosts(ctx context.Context, arg MovePostsParams) error {
+	_, err := q.db.Exec(ctx, movePosts, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
+
+const searchPosts = `-- name: SearchPosts :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.published_at,
+    p.feed_id,
+    f.name AS feed_name,
+    ts_rank(
+        setweight(to_tsvector('english', p.title), 'A') || setweight(to_tsvector('english', coalesce(p.description, '')), 'B'),
+        q
+    ) AS rank,
+    ts_headline(
+        'english',
+        coalesce(p.description, p.title),
+        q,
+        'StartSel=**, StopSel=**, MaxWords=35, MinWords=15, MaxFragments=2'
+    ) AS snippet
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id,
+    websearch_to_tsquery('english', $1) AS q
+WHERE (setweight(to_tsvector('english', p.title), 'A') || setweight(to_tsvector('english', coalesce(p.description, '')), 'B')) @@ q
+  AND ($2::boolean OR EXISTS (
+    SELECT 1 FROM feed_follows ff WHERE ff.feed_id = p.feed_id AND ff.user_id = $3
+  ))
+ORDER BY rank DESC, p.published_at DESC NULLS LAST
+LIMIT $4
+`
+
+type SearchPostsParams struct {
+	Query       string
+	AllFeeds    bool
+	UserID      uuid.UUID
+	ResultLimit int32
+}
+
+type SearchPostsRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	PublishedAt pgtype.Timestamp
+	FeedID      uuid.UUID
+	FeedName    string
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.Query(ctx, searchPosts,
+		arg.Query,
+		arg.AllFeeds,
+		arg.UserID,
+		arg.ResultLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostArticle = `-- name: SetPostArticle :exec
+UPDATE posts
+SET article = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPostArticleParams struct {
+	ID      uuid.UUID
+	Article pgtype.Text
+}
+
+func (q *Queries) SetPostArticle(ctx context.Context, arg SetPostArticleParams) error {
+	_, err := q.db.Exec(ctx, setPostArticle, arg.ID, arg.Article)
+	return err
+}
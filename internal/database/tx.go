@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoTransactions is returned by InTx when the queries run on a
+// connection that can't begin transactions
+var ErrNoTransactions = errors.New("database connection does not support transactions")
+
+// beginner is a pool, connection or transaction that can begin a
+// transaction; a transaction begins a nested one with a savepoint
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// InTx runs fn with queries bound to a new transaction on the pool or
+// connection q uses. The transaction is committed when fn succeeds and
+// rolled back when it fails
+func (q *Queries) InTx(ctx context.Context, fn func(*Queries) error) error {
+	db, ok := q.db.(beginner)
+	if !ok {
+		return ErrNoTransactions
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	// Rolling back after a commit does nothing
+	defer tx.Rollback(ctx)
+
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
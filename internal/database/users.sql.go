@@ -9,30 +9,42 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, name)
+INSERT INTO users (id, name, api_key_hash, password_hash)
 VALUES (
     $1,
-    $2
+    $2,
+    $3,
+    $4
 )
-RETURNING id, name, created_at, updated_at
+RETURNING id, name, created_at, updated_at, api_key_hash, password_hash
 `
 
 type CreateUserParams struct {
-	ID   uuid.UUID
-	Name string
+	ID           uuid.UUID
+	Name         string
+	ApiKeyHash   pgtype.Text
+	PasswordHash pgtype.Text
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, createUser,
+		arg.ID,
+		arg.Name,
+		arg.ApiKeyHash,
+		arg.PasswordHash,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ApiKeyHash,
+		&i.PasswordHash,
 	)
 	return i, err
 }
@@ -47,7 +59,7 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name, created_at, updated_at FROM users WHERE id = $1 LIMIT 1
+SELECT id, name, created_at, updated_at, api_key_hash, password_hash FROM users WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
@@ -58,12 +70,32 @@ func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ApiKeyHash,
+		&i.PasswordHash,
+	)
+	return i, err
+}
+
+const getUserByAPIKeyHash = `-- name: GetUserByAPIKeyHash :one
+SELECT id, name, created_at, updated_at, api_key_hash, password_hash FROM users WHERE api_key_hash = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByAPIKeyHash(ctx context.Context, apiKeyHash pgtype.Text) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByAPIKeyHash, apiKeyHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ApiKeyHash,
+		&i.PasswordHash,
 	)
 	return i, err
 }
 
 const getUserByName = `-- name: GetUserByName :one
-SELECT id, name, created_at, updated_at FROM users WHERE name = $1 LIMIT 1
+SELECT id, name, created_at, updated_at, api_key_hash, password_hash FROM users WHERE name = $1 LIMIT 1
 `
 
 func (q *Queries) GetUserByName(ctx context.Context, name string) (User, error) {
@@ -74,12 +106,14 @@ func (q *Queries) GetUserByName(ctx context.Context, name string) (User, error)
 		&i.Name,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ApiKeyHash,
+		&i.PasswordHash,
 	)
 	return i, err
 }
 
 const getUsers = `-- name: GetUsers :many
-SELECT id, name, created_at, updated_at FROM users ORDER BY name
+SELECT id, name, created_at, updated_at, api_key_hash, password_hash FROM users ORDER BY name
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -96,6 +130,8 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.Name,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ApiKeyHash,
+			&i.PasswordHash,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +142,20 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 	}
 	return items, nil
 }
+
+const setUserAPIKeyHash = `-- name: SetUserAPIKeyHash :exec
+UPDATE users
+SET api_key_hash = $2,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserAPIKeyHashParams struct {
+	ID         uuid.UUID
+	ApiKeyHash pgtype.Text
+}
+
+func (q *Queries) SetUserAPIKeyHash(ctx context.Context, arg SetUserAPIKeyHashParams) error {
+	_, err := q.db.Exec(ctx, setUserAPIKeyHash, arg.ID, arg.ApiKeyHash)
+	return err
+}
@@ -0,0 +1,203 @@
+// Package dbtest provides an in-memory stand-in for the database that
+// service and API tests run sqlc queries against
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DB is a database.DBTX that answers each sqlc query, by name, with canned
+// rows and command tags, and records the queries it runs. Queries run in a
+// transaction it began are recorded with a "tx:" prefix
+type DB struct {
+	// Rows are the rows each query returns
+	Rows map[string][][]any
+	// Answer, when set, returns the rows for a query from its arguments,
+	// or nil to fall back to Rows
+	Answer func(name string, args []any) [][]any
+	// Tags are the command tags Exec returns, such as "UPDATE 1"
+	Tags map[string]string
+	// Errs fail the named queries, and Err fails every query
+	Errs map[string]error
+	Err  error
+
+	// Queries are the names of the queries run, in order
+	Queries []string
+	// Args are the arguments of every call to each query
+	Args map[string][][]any
+	// Tx is the last transaction begun
+	Tx *Tx
+}
+
+// New returns queries that run against db
+func New(db *DB) database.Queries {
+	return *database.New(db)
+}
+
+// Count returns how many times a query ran
+func (db *DB) Count(name string) int {
+	return len(db.Args[name])
+}
+
+// LastArgs returns the arguments of the last call to a query
+func (db *DB) LastArgs(name string) []any {
+	calls := db.Args[name]
+	if len(calls) == 0 {
+		return nil
+	}
+	return calls[len(calls)-1]
+}
+
+// run records a query and returns its name and the error it fails with
+func (db *DB) run(sql string, args []any, inTx bool) (string, error) {
+	name, _, _ := strings.Cut(strings.TrimPrefix(sql, "-- name: "), " ")
+	if inTx {
+		db.Queries = append(db.Queries, "tx:"+name)
+	} else {
+		db.Queries = append(db.Queries, name)
+	}
+	if db.Args == nil {
+		db.Args = make(map[string][][]any)
+	}
+	db.Args[name] = append(db.Args[name], args)
+
+	if db.Err != nil {
+		return name, db.Err
+	}
+	return name, db.Errs[name]
+}
+
+// rows returns the rows for a query
+func (db *DB) rows(name string, args []any) [][]any {
+	if db.Answer != nil {
+		if rows := db.Answer(name, args); rows != nil {
+			return rows
+		}
+	}
+	return db.Rows[name]
+}
+
+func (db *DB) exec(sql string, args []any, inTx bool) (pgconn.CommandTag, error) {
+	name, err := db.run(sql, args, inTx)
+	return pgconn.NewCommandTag(db.Tags[name]), err
+}
+
+func (db *DB) query(sql string, args []any, inTx bool) (pgx.Rows, error) {
+	name, err := db.run(sql, args, inTx)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{rows: db.rows(name, args), index: -1}, nil
+}
+
+func (db *DB) queryRow(sql string, args []any, inTx bool) pgx.Row {
+	name, err := db.run(sql, args, inTx)
+	if err != nil {
+		return &Rows{err: err}
+	}
+	rows := db.rows(name, args)
+	if len(rows) == 0 {
+		return &Rows{err: pgx.ErrNoRows}
+	}
+	return &Rows{rows: rows[:1], index: 0}
+}
+
+func (db *DB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return db.exec(sql, args, false)
+}
+
+func (db *DB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return db.query(sql, args, false)
+}
+
+func (db *DB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	return db.queryRow(sql, args, false)
+}
+
+// Begin starts a transaction whose queries run against db
+func (db *DB) Begin(context.Context) (pgx.Tx, error) {
+	if db.Err != nil {
+		return nil, db.Err
+	}
+	db.Tx = &Tx{db: db}
+	return db.Tx, nil
+}
+
+// Tx is a transaction on a DB. Methods the services don't use are left to
+// the embedded nil pgx.Tx
+type Tx struct {
+	pgx.Tx
+	db         *DB
+	Committed  bool
+	RolledBack bool
+}
+
+func (tx *Tx) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.db.exec(sql, args, true)
+}
+
+func (tx *Tx) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.db.query(sql, args, true)
+}
+
+func (tx *Tx) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.db.queryRow(sql, args, true)
+}
+
+func (tx *Tx) Begin(context.Context) (pgx.Tx, error) {
+	return nil, errors.New("nested transactions are not supported")
+}
+
+func (tx *Tx) Commit(context.Context) error {
+	tx.Committed = true
+	return nil
+}
+
+func (tx *Tx) Rollback(context.Context) error {
+	if !tx.Committed {
+		tx.RolledBack = true
+	}
+	return nil
+}
+
+// Rows implements pgx.Rows and pgx.Row over in-memory values
+type Rows struct {
+	rows  [][]any
+	index int
+	err   error
+}
+
+func (r *Rows) Close()                                       {}
+func (r *Rows) Err() error                                   { return r.err }
+func (r *Rows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *Rows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *Rows) Values() ([]any, error)                       { return r.rows[r.index], nil }
+func (r *Rows) RawValues() [][]byte                          { return nil }
+func (r *Rows) Conn() *pgx.Conn                              { return nil }
+
+func (r *Rows) Next() bool {
+	r.index++
+	return r.index < len(r.rows)
+}
+
+func (r *Rows) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	row := r.rows[r.index]
+	if len(dest) != len(row) {
+		return fmt.Errorf("scan into %d values, row has %d", len(dest), len(row))
+	}
+	for i, value := range row {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
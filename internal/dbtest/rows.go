@@ -0,0 +1,24 @@
+package dbtest
+
+import "github.com/abahnj/rssagg/internal/database"
+
+// FeedRow is a row of the feeds table, as queries returning feeds scan it
+func FeedRow(feed database.Feed) []any {
+	return []any{feed.ID, feed.Name, feed.Url, feed.UserID, feed.CreatedAt, feed.UpdatedAt,
+		feed.LastFetchedAt, feed.Etag, feed.LastModified, feed.FetchInterval, feed.FetchIntervalOverride,
+		feed.NextFetchAt, feed.ConsecutiveFailures, feed.LastError, feed.LastSuccessAt, feed.DisabledAt,
+		feed.FetchFullArticle, feed.PublisherMinInterval, feed.SkipHours}
+}
+
+// PostRow is a row of the posts table, as queries returning posts scan it
+func PostRow(post database.Post) []any {
+	return []any{post.ID, post.CreatedAt, post.UpdatedAt, post.Title, post.Url, post.Description,
+		post.PublishedAt, post.FeedID, post.Guid, post.CanonicalUrl, post.DuplicateOf, post.Content,
+		post.Authors, post.Categories, post.DurationSeconds, post.Episode, post.ImageUrl, post.Article}
+}
+
+// PostsForUserRow is a GetPostsForUser row for a post
+func PostsForUserRow(p database.GetPostsForUserRow) []any {
+	return []any{p.ID, p.CreatedAt, p.UpdatedAt, p.Title, p.Url, p.Description, p.PublishedAt,
+		p.FeedID, p.Content, p.Authors, p.Categories, p.FeedName, p.IsRead, p.SortAt}
+}
@@ -4,10 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/abahnj/rssagg/internal/cli"
 	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/opml"
+)
+
+const (
+	// defaultConcurrency is the number of feeds agg scrapes per tick
+	defaultConcurrency = 1
+	// defaultFeedTimeout bounds how long a single feed scrape may take
+	defaultFeedTimeout = 30 * time.Second
+	// feedErrorHistoryLimit is the number of recent errors feedhealth shows per feed
+	feedErrorHistoryLimit = 5
 )
 
 // HandlerAggregator handles the agg command to fetch and display feeds
@@ -22,18 +34,39 @@ func HandlerAggregator(s *cli.State, cmd cli.Command) error {
 		return fmt.Errorf("invalid time format: %w", err)
 	}
 	
+	// Parse the number of feeds to scrape in parallel if provided
+	concurrency := defaultConcurrency
+	if len(cmd.Args) > 1 {
+		concurrency, err = strconv.Atoi(cmd.Args[1])
+		if err != nil || concurrency < 1 {
+			return fmt.Errorf("invalid concurrency value: %s", cmd.Args[1])
+		}
+	}
+	
+	// Parse the per-feed timeout if provided
+	feedTimeout := defaultFeedTimeout
+	if len(cmd.Args) > 2 {
+		feedTimeout, err = time.ParseDuration(cmd.Args[2])
+		if err != nil {
+			return fmt.Errorf("invalid timeout format: %w", err)
+		}
+	}
+	
 	service := NewService(*s.Db)
+	if s.Config != nil && s.Config.MaxFeedFailures > 0 {
+		service.MaxFailures = s.Config.MaxFeedFailures
+	}
 	ctx := context.Background()
 	
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenRequests)
+	fmt.Printf("Collecting up to %d feeds every %s\n", concurrency, timeBetweenRequests)
 	
 	// Create a ticker to run the scrape function periodically
 	ticker := time.NewTicker(timeBetweenRequests)
 	
 	// Run immediately and then on each tick
 	for ; ; <-ticker.C {
-		if err := service.ScrapeFeed(ctx); err != nil {
-			fmt.Printf("Error scraping feed: %v\n", err)
+		if err := service.ScrapeFeeds(ctx, concurrency, feedTimeout); err != nil {
+			fmt.Printf("Error scraping feeds: %v\n", err)
 		}
 	}
 }
@@ -48,15 +81,25 @@ func HandlerAddFeed(s *cli.State, cmd cli.Command, user database.User) error {
 	feedName := cmd.Args[0]
 	feedURL := cmd.Args[1]
 	
+	// Parse the optional fixed refresh interval
+	var fetchInterval time.Duration
+	if len(cmd.Args) > 2 {
+		var err error
+		fetchInterval, err = time.ParseDuration(cmd.Args[2])
+		if err != nil || fetchInterval <= 0 {
+			return fmt.Errorf("invalid refresh interval: %s", cmd.Args[2])
+		}
+	}
+	
 	service := NewService(*s.Db)
 	
 	// Create or retrieve the feed
-	feed, err := service.CreateFeed(ctx, feedName, feedURL, user.ID)
+	feed, created, err := service.AddFeed(ctx, feedName, feedURL, user.ID)
 	if err != nil {
 		return err
 	}
 	
-	if feed.Name != feedName {
+	if !created {
 		fmt.Printf("Feed already exists, using existing feed: %s\n", feed.Name)
 	} else {
 		// Print out the new feed details
@@ -67,6 +110,17 @@ func HandlerAddFeed(s *cli.State, cmd cli.Command, user database.User) error {
 		fmt.Printf("  Created: %s\n", feed.CreatedAt.Time.Format("2006-01-02 15:04:05"))
 	}
 	
+	// The interval is only set on feeds this command creates; an existing
+	// feed is shared with its other followers and keeps its schedule
+	if fetchInterval > 0 && created {
+		if err := service.SetFetchIntervalOverride(ctx, feed.ID, fetchInterval); err != nil {
+			return err
+		}
+		fmt.Printf("Feed will be refreshed every %s\n", fetchInterval)
+	} else if fetchInterval > 0 {
+		fmt.Printf("Refresh interval not changed, as the feed is shared with its other followers\n")
+	}
+	
 	// Now create a feed follow for the current user
 	followParams := database.CreateFeedFollowParams{
 		ID:     feed.ID,
@@ -96,23 +150,13 @@ func HandlerListFeeds(s *cli.State, cmd cli.Command) error {
 		return err
 	}
 	
-	if len(feeds) == 0 {
-		fmt.Println("No feeds found")
-		return nil
+	table := cli.NewTable("name", "url", "added_by", "created_at")
+	table.Empty = "No feeds found"
+	for _, feed := range feeds {
+		table.AddRow(feed.Name, feed.Url, feed.UserName, feed.CreatedAt.Time)
 	}
 	
-	fmt.Printf("Found %d feeds:\n\n", len(feeds))
-	
-	// Display each feed with user information
-	for i, feed := range feeds {
-		fmt.Printf("%d. %s\n", i+1, feed.Name)
-		fmt.Printf("   URL: %s\n", feed.Url)
-		fmt.Printf("   Added by: %s\n", feed.UserName)
-		fmt.Printf("   Added on: %s\n", feed.CreatedAt.Time.Format("2006-01-02 15:04:05"))
-		fmt.Println()
-	}
-	
-	return nil
+	return s.Render(table)
 }
 
 // HandlerFollowFeed handles the follow command to follow an existing feed
@@ -165,6 +209,27 @@ func HandlerUnfollowFeed(s *cli.State, cmd cli.Command, user database.User) erro
 	return nil
 }
 
+// HandlerFullText handles the fulltext command to turn fetching the full
+// article behind each new post on or off for a feed
+func HandlerFullText(s *cli.State, cmd cli.Command, user database.User) error {
+	if len(cmd.Args) < 2 || (cmd.Args[1] != "on" && cmd.Args[1] != "off") {
+		return errors.New("usage: fulltext <feed-url> on|off")
+	}
+	
+	service := NewService(*s.Db)
+	feed, err := service.SetFetchFullArticle(context.Background(), cmd.Args[0], user.ID, cmd.Args[1] == "on")
+	if err != nil {
+		return err
+	}
+	
+	if feed.FetchFullArticle {
+		fmt.Printf("Full articles will be fetched for new posts in \"%s\"\n", feed.Name)
+	} else {
+		fmt.Printf("Full articles will no longer be fetched for \"%s\"\n", feed.Name)
+	}
+	return nil
+}
+
 // HandlerListFollowing handles the following command to list feeds the user follows
 func HandlerListFollowing(s *cli.State, cmd cli.Command, user database.User) error {
 	ctx := context.Background()
@@ -177,21 +242,141 @@ func HandlerListFollowing(s *cli.State, cmd cli.Command, user database.User) err
 		return err
 	}
 	
-	if len(feedFollows) == 0 {
-		fmt.Printf("User %s is not following any feeds\n", user.Name)
+	table := cli.NewTable("name", "url", "followed_at")
+	table.Empty = fmt.Sprintf("User %s is not following any feeds", user.Name)
+	for _, follow := range feedFollows {
+		table.AddRow(follow.FeedName, follow.FeedUrl, follow.CreatedAt.Time)
+	}
+	
+	return s.Render(table)
+}
+
+// HandlerFeedHealth handles the feedhealth command to list failing and
+// disabled feeds, or to re-enable a feed with "feedhealth enable <url>"
+func HandlerFeedHealth(s *cli.State, cmd cli.Command) error {
+	ctx := context.Background()
+	
+	service := NewService(*s.Db)
+	
+	if len(cmd.Args) > 0 {
+		if cmd.Args[0] != "enable" || len(cmd.Args) < 2 {
+			return errors.New("usage: feedhealth [enable <url>]")
+		}
+		
+		feed, err := service.EnableFeed(ctx, cmd.Args[1])
+		if err != nil {
+			return err
+		}
+		
+		fmt.Printf("Feed \"%s\" enabled\n", feed.Name)
 		return nil
 	}
 	
-	fmt.Printf("User %s is following %d feeds:\n\n", user.Name, len(feedFollows))
+	feeds, err := service.GetUnhealthyFeeds(ctx)
+	if err != nil {
+		return err
+	}
+	
+	table := cli.NewTable("name", "url", "status", "consecutive_failures", "last_success_at", "disabled_at", "recent_errors")
+	table.Empty = "All feeds are healthy"
+	for _, feed := range feeds {
+		status := "failing"
+		if feed.DisabledAt.Valid {
+			status = "disabled"
+		}
+		
+		// Include the most recent errors for this feed
+		feedErrors, err := service.GetFeedErrors(ctx, feed.ID, feedErrorHistoryLimit)
+		if err != nil {
+			return err
+		}
+		recentErrors := make([]string, 0, len(feedErrors))
+		for _, feedError := range feedErrors {
+			recentErrors = append(recentErrors, feedError.CreatedAt.Time.Format("2006-01-02 15:04:05")+" "+feedError.Error)
+		}
+		
+		table.AddRow(feed.Name, feed.Url, status, feed.ConsecutiveFailures, cli.NullableTime(feed.LastSuccessAt), cli.NullableTime(feed.DisabledAt), recentErrors)
+	}
+	
+	return s.Render(table)
+}
+
+// HandlerImport handles the import command to follow every feed in an OPML file
+func HandlerImport(s *cli.State, cmd cli.Command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return errors.New("OPML file path is required")
+	}
 	
-	// Display each followed feed
-	for i, follow := range feedFollows {
-		fmt.Printf("%d. %s\n", i+1, follow.FeedName)
-		fmt.Printf("   URL: %s\n", follow.FeedUrl)
-		fmt.Printf("   Following since: %s\n", follow.CreatedAt.Time.Format("2006-01-02 15:04:05"))
-		fmt.Println()
+	file, err := os.Open(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open OPML file: %w", err)
 	}
+	defer file.Close()
+	
+	doc, err := opml.Parse(file)
+	if err != nil {
+		return err
+	}
+	
+	ctx := context.Background()
+	service := NewService(*s.Db)
+	
+	summary := service.ImportFeeds(ctx, doc.Feeds(), user.ID)
+	
+	for _, feed := range summary.Added {
+		fmt.Printf("Added: %s (%s)\n", feed.Title, feed.URL)
+	}
+	for _, feed := range summary.AlreadyFollowed {
+		fmt.Printf("Already following: %s (%s)\n", feed.Title, feed.URL)
+	}
+	for _, invalid := range summary.Invalid {
+		fmt.Printf("Invalid: %s (%s): %v\n", invalid.Feed.Title, invalid.Feed.URL, invalid.Err)
+	}
+	
+	fmt.Printf("\nProcessed %d entries: %d added, %d already followed, %d invalid\n",
+		len(summary.Added)+len(summary.AlreadyFollowed)+len(summary.Invalid),
+		len(summary.Added), len(summary.AlreadyFollowed), len(summary.Invalid))
 	
 	return nil
 }
 
+// HandlerExport handles the export command to write the user's follows as
+// OPML, either to the given file or to standard output
+func HandlerExport(s *cli.State, cmd cli.Command, user database.User) error {
+	ctx := context.Background()
+	
+	service := NewService(*s.Db)
+	
+	feedFollows, err := service.GetFollowedFeeds(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	
+	subscriptions := make([]opml.Feed, 0, len(feedFollows))
+	for _, follow := range feedFollows {
+		subscriptions = append(subscriptions, opml.Feed{
+			Title: follow.FeedName,
+			URL:   follow.FeedUrl,
+		})
+	}
+	
+	doc := opml.NewDocument(fmt.Sprintf("%s's subscriptions", user.Name), subscriptions, time.Now())
+	
+	// Write to standard output when no file is given
+	if len(cmd.Args) < 1 {
+		return doc.Write(os.Stdout)
+	}
+	
+	file, err := os.Create(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("failed to create OPML file: %w", err)
+	}
+	defer file.Close()
+	
+	if err := doc.Write(file); err != nil {
+		return err
+	}
+	
+	fmt.Printf("Exported %d feeds to %s\n", len(subscriptions), cmd.Args[0])
+	return nil
+}
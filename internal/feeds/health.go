@@ -0,0 +1,105 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// DefaultMaxFailures is the number of consecutive failures after which a feed is disabled
+	DefaultMaxFailures = 10
+	// MaxFailureBackoff caps how long a failing feed waits before its next attempt
+	MaxFailureBackoff = 7 * 24 * time.Hour
+)
+
+// FailureBackoff returns how long to wait before retrying a feed, doubling
+// its fetch interval for each consecutive failure after the first
+func FailureBackoff(interval time.Duration, failures int) time.Duration {
+	if interval < MinFetchInterval {
+		interval = MinFetchInterval
+	}
+
+	backoff := interval
+	for i := 1; i < failures; i++ {
+		backoff *= 2
+		if backoff >= MaxFailureBackoff {
+			return MaxFailureBackoff
+		}
+	}
+	return backoff
+}
+
+// RecordFailure stores a failed fetch, backs the feed off exponentially and
+// disables it once it reaches the failure limit
+func (s *Service) RecordFailure(ctx context.Context, feed database.Feed, fetchErr error) (database.Feed, error) {
+	maxFailures := s.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxFailures
+	}
+
+	errorText := fetchErr.Error()
+	failures := int(feed.ConsecutiveFailures) + 1
+
+	if err := s.DB.CreateFeedError(ctx, database.CreateFeedErrorParams{
+		ID:     uuid.New(),
+		FeedID: feed.ID,
+		Error:  errorText,
+	}); err != nil {
+		return database.Feed{}, fmt.Errorf("failed to record feed error: %w", err)
+	}
+
+	params := database.RecordFeedFailureParams{
+		ID:          feed.ID,
+		LastError:   pgtype.Text{String: errorText, Valid: true},
+		Delay:       durationToInterval(FailureBackoff(intervalToDuration(feed.FetchInterval), failures)),
+		MaxFailures: int32(maxFailures),
+	}
+
+	updated, err := s.DB.RecordFeedFailure(ctx, params)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("failed to record feed failure: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetUnhealthyFeeds returns feeds that are failing or have been disabled
+func (s *Service) GetUnhealthyFeeds(ctx context.Context) ([]database.Feed, error) {
+	feeds, err := s.DB.GetUnhealthyFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unhealthy feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// GetFeedErrors returns the most recent fetch errors for a feed
+func (s *Service) GetFeedErrors(ctx context.Context, feedID uuid.UUID, limit int32) ([]database.FeedError, error) {
+	params := database.GetFeedErrorsParams{
+		FeedID: feedID,
+		Limit:  limit,
+	}
+
+	feedErrors, err := s.DB.GetFeedErrors(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed errors: %w", err)
+	}
+	return feedErrors, nil
+}
+
+// EnableFeed re-enables a disabled feed and clears its failure count
+func (s *Service) EnableFeed(ctx context.Context, feedURL string) (database.Feed, error) {
+	feed, err := s.DB.GetFeedByURL(ctx, feedURL)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("feed with URL %s not found: %w", feedURL, err)
+	}
+
+	if err := s.DB.EnableFeed(ctx, feed.ID); err != nil {
+		return database.Feed{}, fmt.Errorf("failed to enable feed: %w", err)
+	}
+	return feed, nil
+}
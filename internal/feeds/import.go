@@ -0,0 +1,74 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/abahnj/rssagg/internal/opml"
+	"github.com/google/uuid"
+)
+
+// ImportSummary reports the outcome of importing a list of subscriptions
+type ImportSummary struct {
+	Added           []opml.Feed
+	AlreadyFollowed []opml.Feed
+	Invalid         []ImportError
+}
+
+// ImportError describes a subscription that could not be imported
+type ImportError struct {
+	Feed opml.Feed
+	Err  error
+}
+
+// ImportFeeds creates any missing feeds and follows each of them for the user
+func (s *Service) ImportFeeds(ctx context.Context, subscriptions []opml.Feed, userID uuid.UUID) ImportSummary {
+	var summary ImportSummary
+
+	for _, subscription := range subscriptions {
+		if err := validateFeedURL(subscription.URL); err != nil {
+			summary.Invalid = append(summary.Invalid, ImportError{Feed: subscription, Err: err})
+			continue
+		}
+
+		name := subscription.Title
+		if name == "" {
+			name = subscription.URL
+		}
+
+		feed, err := s.CreateFeed(ctx, name, subscription.URL, userID)
+		if err != nil {
+			summary.Invalid = append(summary.Invalid, ImportError{Feed: subscription, Err: err})
+			continue
+		}
+
+		_, err = s.FollowFeed(ctx, feed.Url, userID)
+		switch {
+		case errors.Is(err, ErrAlreadyFollowing):
+			summary.AlreadyFollowed = append(summary.AlreadyFollowed, subscription)
+		case err != nil:
+			summary.Invalid = append(summary.Invalid, ImportError{Feed: subscription, Err: err})
+		default:
+			summary.Added = append(summary.Added, subscription)
+		}
+	}
+
+	return summary
+}
+
+// validateFeedURL checks that a subscription URL is an absolute HTTP(S) URL
+func validateFeedURL(feedURL string) error {
+	parsed, err := url.Parse(feedURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme: %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return errors.New("URL has no host")
+	}
+	return nil
+}
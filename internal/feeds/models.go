@@ -2,89 +2,173 @@ package feeds
 
 import (
 	"context"
-	"encoding/xml"
 	"errors"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/abahnj/rssagg/internal/database"
 	"github.com/abahnj/rssagg/internal/posts"
 	"github.com/abahnj/rssagg/internal/types"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // Using common RSS types from the types package
 
+// ErrAlreadyFollowing is returned when a user follows a feed they already follow
+var ErrAlreadyFollowing = errors.New("you are already following this feed")
+
+// ErrNotFollowing is returned when a user changes a feed they don't follow
+var ErrNotFollowing = errors.New("you are not following this feed")
+
 // Service handles feed operations
 type Service struct {
 	DB database.Queries
+	// MaxFailures is the number of consecutive failures after which a feed is disabled
+	MaxFailures int
 }
 
 // NewService creates a new feed service
 func NewService(db database.Queries) *Service {
 	return &Service{
-		DB: db,
+		DB:          db,
+		MaxFailures: DefaultMaxFailures,
 	}
 }
 
-// FetchFeed retrieves and parses an RSS feed from the given URL
+// FetchResult holds the outcome of a conditional feed fetch
+type FetchResult struct {
+	// Feed is the parsed feed, or nil when the server reported no changes
+	Feed *types.RSSFeed
+	// NotModified is true when the server answered 304 Not Modified
+	NotModified bool
+	// ETag and LastModified are the cache validators to send on the next fetch
+	ETag         string
+	LastModified string
+	// PermanentURL is the feed's new location when the request was answered
+	// only by permanent (301/308) redirects, and empty otherwise
+	PermanentURL string
+}
+
+// FetchFeed retrieves and parses an RSS, Atom or JSON feed from the given URL
 func (s *Service) FetchFeed(ctx context.Context, feedURL string) (*types.RSSFeed, error) {
+	result, err := s.FetchFeedConditional(ctx, feedURL, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return result.Feed, nil
+}
+
+// FetchFeedConditional retrieves a feed, sending If-None-Match and
+// If-Modified-Since when validators from a previous fetch are available
+func (s *Service) FetchFeedConditional(ctx context.Context, feedURL, etag, lastModified string) (FetchResult, error) {
 	// Create a new request with context
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return FetchResult{}, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Set User-Agent header to identify our program
 	req.Header.Set("User-Agent", "gator")
 
+	// Send cache validators from the previous fetch
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	// Follow redirects, remembering where an unbroken chain of permanent
+	// redirects leads so the stored URL can be updated
+	permanentURL := ""
+	permanent := true
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if permanent && isPermanentRedirect(req.Response.StatusCode) {
+				permanentURL = req.URL.String()
+			} else {
+				permanent = false
+			}
+			return nil
+		},
+	}
+
 	// Make the HTTP request
-	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching feed: %w", err)
+		return FetchResult{}, fmt.Errorf("error fetching feed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// The feed hasn't changed, so keep the validators we already have
+	if resp.StatusCode == http.StatusNotModified {
+		return FetchResult{
+			NotModified:  true,
+			ETag:         etag,
+			LastModified: lastModified,
+			PermanentURL: permanentURL,
+		}, nil
+	}
+
 	// Check for non-success status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return FetchResult{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	// Read the response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return FetchResult{}, fmt.Errorf("error reading response body: %w", err)
 	}
 
-	// Parse the XML
-	var feed types.RSSFeed
-	if err := xml.Unmarshal(body, &feed); err != nil {
-		return nil, fmt.Errorf("error parsing XML: %w", err)
+	// Parse the document, detecting its format from the content type or body
+	feed, err := parseFeed(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return FetchResult{}, err
 	}
 
 	// Unescape HTML entities in the channel's title and description
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
 	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
 
-	// Unescape HTML entities in each item's title and description
+	// Unescape HTML entities in each item's title. Descriptions are HTML, which
+	// the parsers have already decoded once, so unescaping them again would
+	// turn escaped text into markup
 	for i := range feed.Channel.Item {
 		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
 	}
 
-	return &feed, nil
+	return FetchResult{
+		Feed:         feed,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		PermanentURL: permanentURL,
+	}, nil
 }
 
-// CreateFeed adds a new feed to the database
+// CreateFeed adds a new feed to the database, or returns the existing feed
+// with the same URL
 func (s *Service) CreateFeed(ctx context.Context, name, url string, userID uuid.UUID) (database.Feed, error) {
+	feed, _, err := s.AddFeed(ctx, name, url, userID)
+	return feed, err
+}
+
+// AddFeed is CreateFeed, also reporting whether the feed was created rather
+// than already existing
+func (s *Service) AddFeed(ctx context.Context, name, url string, userID uuid.UUID) (database.Feed, bool, error) {
 	// Check if feed already exists
 	existingFeed, err := s.DB.GetFeedByURL(ctx, url)
 	if err == nil {
 		// Feed already exists
-		return existingFeed, nil
+		return existingFeed, false, nil
 	}
 
 	// Create a new feed record
@@ -97,10 +181,10 @@ func (s *Service) CreateFeed(ctx context.Context, name, url string, userID uuid.
 
 	feed, err := s.DB.CreateFeed(ctx, createFeedParams)
 	if err != nil {
-		return database.Feed{}, fmt.Errorf("failed to create feed: %w", err)
+		return database.Feed{}, false, fmt.Errorf("failed to create feed: %w", err)
 	}
 
-	return feed, nil
+	return feed, true, nil
 }
 
 // GetAllFeeds returns all feeds with their creator information
@@ -130,7 +214,7 @@ func (s *Service) FollowFeed(ctx context.Context, feedURL string, userID uuid.UU
 	feedFollow, err := s.DB.CreateFeedFollow(ctx, createFeedFollowParams)
 	if err != nil {
 		if err.Error() == "ERROR: duplicate key value violates unique constraint \"feed_follows_user_id_feed_id_key\" (SQLSTATE 23505)" {
-			return database.CreateFeedFollowRow{}, errors.New("you are already following this feed")
+			return database.CreateFeedFollowRow{}, ErrAlreadyFollowing
 		}
 		return database.CreateFeedFollowRow{}, fmt.Errorf("failed to follow feed: %w", err)
 	}
@@ -162,56 +246,222 @@ func (s *Service) UnfollowFeed(ctx context.Context, feedURL string, userID uuid.
 	return nil
 }
 
-// GetNextFeedToFetch gets the next feed that should be fetched
-func (s *Service) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
-	feed, err := s.DB.GetNextFeedToFetch(ctx)
+// MarkFeedFetched records a successful fetch and resets the feed's failure count
+func (s *Service) MarkFeedFetched(ctx context.Context, feedID uuid.UUID) error {
+	err := s.DB.MarkFeedFetched(ctx, feedID)
 	if err != nil {
-		return database.Feed{}, fmt.Errorf("failed to get next feed to fetch: %w", err)
+		return fmt.Errorf("failed to mark feed as fetched: %w", err)
 	}
-	return feed, nil
+	return nil
 }
 
-// MarkFeedFetched updates the last_fetched_at timestamp for a feed
-func (s *Service) MarkFeedFetched(ctx context.Context, feedID uuid.UUID) error {
-	err := s.DB.MarkFeedFetched(ctx, feedID)
+// UpdateCacheValidators stores the ETag and Last-Modified values for a feed
+func (s *Service) UpdateCacheValidators(ctx context.Context, feedID uuid.UUID, etag, lastModified string) error {
+	params := database.UpdateFeedCacheValidatorsParams{
+		ID:           feedID,
+		Etag:         pgtype.Text{String: etag, Valid: etag != ""},
+		LastModified: pgtype.Text{String: lastModified, Valid: lastModified != ""},
+	}
+
+	err := s.DB.UpdateFeedCacheValidators(ctx, params)
 	if err != nil {
-		return fmt.Errorf("failed to mark feed as fetched: %w", err)
+		return fmt.Errorf("failed to update feed cache validators: %w", err)
 	}
 	return nil
 }
 
-// ScrapeFeed fetches and processes a single feed
-func (s *Service) ScrapeFeed(ctx context.Context) error {
-	// Import posts service
-	postsService := posts.NewService(s.DB)
+// ScheduleNextFetch adapts the feed's fetch interval to its latest fetch and
+// records when it is next due, honouring the user's override and the
+// publisher's polling hints. The hints are stored with the feed so fetches
+// that find it unchanged can still honour them
+func (s *Service) ScheduleNextFetch(ctx context.Context, feed database.Feed, newPosts int, schedule PublisherSchedule) error {
+	var interval time.Duration
+	if feed.FetchIntervalOverride.Valid {
+		interval = intervalToDuration(feed.FetchIntervalOverride)
+	} else {
+		interval = AdaptFetchInterval(intervalToDuration(feed.FetchInterval), newPosts)
+		if interval < schedule.MinInterval {
+			interval = schedule.MinInterval
+		}
+	}
 	
-	// Get the next feed to fetch
-	feed, err := s.GetNextFeedToFetch(ctx)
+	delay := NextFetchDelay(time.Now(), interval, schedule.SkipHours)
+	
+	params := database.ScheduleFeedFetchParams{
+		ID:            feed.ID,
+		FetchInterval: durationToInterval(interval),
+		Delay:         durationToInterval(delay),
+	}
+	if schedule.MinInterval > 0 {
+		params.PublisherMinInterval = durationToInterval(schedule.MinInterval)
+	}
+	for _, hour := range schedule.SkipHours {
+		params.SkipHours = append(params.SkipHours, int32(hour))
+	}
+	
+	if err := s.DB.ScheduleFeedFetch(ctx, params); err != nil {
+		return fmt.Errorf("failed to schedule next fetch: %w", err)
+	}
+	return nil
+}
+
+// SetFetchIntervalOverride pins a feed to a fixed fetch interval instead of
+// the adaptive schedule
+func (s *Service) SetFetchIntervalOverride(ctx context.Context, feedID uuid.UUID, interval time.Duration) error {
+	params := database.SetFeedFetchIntervalOverrideParams{
+		ID:                    feedID,
+		FetchIntervalOverride: durationToInterval(interval),
+	}
+	
+	if err := s.DB.SetFeedFetchIntervalOverride(ctx, params); err != nil {
+		return fmt.Errorf("failed to set fetch interval: %w", err)
+	}
+	return nil
+}
+
+// SetFetchFullArticle turns fetching the full article behind each new post
+// on or off for a feed the user follows
+func (s *Service) SetFetchFullArticle(ctx context.Context, feedURL string, userID uuid.UUID, enabled bool) (database.Feed, error) {
+	feed, err := s.DB.GetFeedByURL(ctx, feedURL)
 	if err != nil {
-		return fmt.Errorf("failed to get next feed to fetch: %w", err)
+		return database.Feed{}, fmt.Errorf("feed with URL %s not found: %w", feedURL, err)
 	}
 	
+	params := database.SetFeedFetchFullArticleParams{
+		ID:               feed.ID,
+		FetchFullArticle: enabled,
+		UserID:           userID,
+	}
+	count, err := s.DB.SetFeedFetchFullArticle(ctx, params)
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("failed to set full article mode: %w", err)
+	}
+	// Only the feed's followers may change how it's fetched
+	if count == 0 {
+		return database.Feed{}, ErrNotFollowing
+	}
+	
+	feed.FetchFullArticle = enabled
+	return feed, nil
+}
+
+// ClaimFeedsToFetch claims up to limit feeds that are due for fetching,
+// skipping feeds already claimed by another aggregator process
+func (s *Service) ClaimFeedsToFetch(ctx context.Context, limit int32) ([]database.Feed, error) {
+	feeds, err := s.DB.ClaimFeedsToFetch(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim feeds to fetch: %w", err)
+	}
+	return feeds, nil
+}
+
+// ScrapeFeeds claims up to concurrency feeds and scrapes them in parallel,
+// giving each feed at most timeout to complete
+func (s *Service) ScrapeFeeds(ctx context.Context, concurrency int, timeout time.Duration) error {
+	feeds, err := s.ClaimFeedsToFetch(ctx, int32(concurrency))
+	if err != nil {
+		return err
+	}
+	
+	var wg sync.WaitGroup
+	for _, feed := range feeds {
+		wg.Add(1)
+		go func(feed database.Feed) {
+			defer wg.Done()
+			
+			feedCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			
+			if err := s.scrapeFeed(feedCtx, feed); err != nil {
+				fmt.Printf("[%s] Error scraping feed: %v\n", feed.Name, err)
+			}
+		}(feed)
+	}
+	wg.Wait()
+	
+	return nil
+}
+
+// scrapeFeed fetches the given feed and stores its new posts
+func (s *Service) scrapeFeed(ctx context.Context, feed database.Feed) error {
+	postsService := posts.NewService(s.DB)
+	
 	// Log which feed we're about to fetch
-	fmt.Printf("\nFetching feed: %s (%s)\n", feed.Name, feed.Url)
+	fmt.Printf("[%s] Fetching feed: %s\n", feed.Name, feed.Url)
 	
-	// Fetch the feed content
-	rssFeed, err := s.FetchFeed(ctx, feed.Url)
+	// Fetch the feed content, skipping the download if it hasn't changed
+	result, err := s.FetchFeedConditional(ctx, feed.Url, feed.Etag.String, feed.LastModified.String)
 	if err != nil {
+		// Back the feed off so it doesn't block the rest of the queue,
+		// even if the fetch failed because the scrape timed out
+		updated, recordErr := s.RecordFailure(context.WithoutCancel(ctx), feed, err)
+		if recordErr != nil {
+			return recordErr
+		}
+		if updated.DisabledAt.Valid {
+			fmt.Printf("[%s] Feed disabled after %d consecutive failures\n", feed.Name, updated.ConsecutiveFailures)
+		}
 		return fmt.Errorf("failed to fetch feed content: %w", err)
 	}
 	
+	// Follow the publisher to the feed's new home
+	if result.PermanentURL != "" && result.PermanentURL != feed.Url {
+		moved, err := s.MoveFeed(ctx, feed, result.PermanentURL)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[%s] Feed moved permanently from %s to %s\n", feed.Name, feed.Url, moved.Url)
+		if moved.ID != feed.ID {
+			fmt.Printf("[%s] Merged into existing feed \"%s\"\n", feed.Name, moved.Name)
+		}
+		feed = moved
+	}
+	
+	if result.NotModified {
+		fmt.Printf("[%s] Feed not modified since last fetch\n", feed.Name)
+		if err := s.MarkFeedFetched(ctx, feed.ID); err != nil {
+			return err
+		}
+		// The unchanged feed carries no hints, so reuse the last ones seen
+		return s.ScheduleNextFetch(ctx, feed, 0, StoredPublisherSchedule(feed))
+	}
+	
+	rssFeed := result.Feed
+	
+	// Remember the validators for the next conditional fetch
+	if err := s.UpdateCacheValidators(ctx, feed.ID, result.ETag, result.LastModified); err != nil {
+		return err
+	}
+	
 	// Process the feed items
-	fmt.Printf("Found %d posts in feed\n", len(rssFeed.Channel.Item))
+	fmt.Printf("[%s] Found %d posts in feed\n", feed.Name, len(rssFeed.Channel.Item))
+	
+	// Load the followers' filter rules so new posts can be starred; a
+	// failure only skips starring
+	filters, err := postsService.GetFeedFilters(ctx, feed.ID)
+	if err != nil {
+		fmt.Printf("[%s] Error loading filter rules: %v\n", feed.Name, err)
+	}
 	
 	// Store each post in the database
+	newPosts := 0
 	for _, item := range rssFeed.Channel.Item {
 		result := postsService.CreatePost(ctx, feed, item)
 		if result.Err != nil {
 			// Just log errors but continue processing other items
-			fmt.Printf("Error saving post %s: %v\n", item.Title, result.Err)
+			fmt.Printf("[%s] Error saving post %s: %v\n", feed.Name, item.Title, result.Err)
 		} else if result.Created {
 			// Only print "Saved" for newly created posts
-			fmt.Printf("Saved: %s\n", item.Title)
+			fmt.Printf("[%s] Saved: %s\n", feed.Name, item.Title)
+			newPosts++
+		}
+		if result.Created && len(filters) > 0 {
+			starred, err := postsService.StarMatching(ctx, filters, feed.ID, result.PostID, item.Title, item.Description)
+			if err != nil {
+				fmt.Printf("[%s] Error starring post %s: %v\n", feed.Name, item.Title, err)
+			} else if starred > 0 {
+				fmt.Printf("[%s] Starred for %d users by filter rules: %s\n", feed.Name, starred, item.Title)
+			}
 		}
 	}
 	
@@ -221,5 +471,32 @@ func (s *Service) ScrapeFeed(ctx context.Context) error {
 		return fmt.Errorf("failed to mark feed as fetched: %w", err)
 	}
 	
+	// Poll busy feeds more often and quiet feeds less often
+	if err := s.ScheduleNextFetch(ctx, feed, newPosts, NewPublisherSchedule(rssFeed.Channel.ScheduleHints)); err != nil {
+		return err
+	}
+	
+	// Fetch the full article behind new posts for feeds that opted in. The
+	// pages get their own timeouts rather than what's left of the feed's
+	if feed.FetchFullArticle {
+		s.fetchArticles(context.WithoutCancel(ctx), postsService, feed)
+	}
+	
 	return nil
-}
\ No newline at end of file
+}
+
+// fetchArticles fetches the article behind each of a feed's recent posts
+// that doesn't have one yet, so pages that failed are retried next scrape
+func (s *Service) fetchArticles(ctx context.Context, postsService *posts.Service, feed database.Feed) {
+	pending, err := postsService.PostsMissingArticle(ctx, feed.ID)
+	if err != nil {
+		fmt.Printf("[%s] Error listing posts to fetch articles for: %v\n", feed.Name, err)
+		return
+	}
+	
+	for _, post := range pending {
+		if err := postsService.FetchArticle(ctx, post.ID, post.Url); err != nil {
+			fmt.Printf("[%s] Error fetching article %s: %v\n", feed.Name, post.Url, err)
+		}
+	}
+}
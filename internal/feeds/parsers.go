@@ -0,0 +1,288 @@
+package feeds
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/types"
+)
+
+// parseFeed detects the format of a feed document from its content type or
+// root element and normalizes it into the RSS item model
+func parseFeed(body []byte, contentType string) (*types.RSSFeed, error) {
+	if isJSONFeed(body, contentType) {
+		return parseJSONFeed(body)
+	}
+
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing XML: %w", err)
+	}
+
+	switch root.Local {
+	case "feed":
+		return parseAtom(body)
+	case "RDF":
+		return parseRDF(body)
+	default:
+		return parseRSS(body)
+	}
+}
+
+// isJSONFeed reports whether a response holds a JSON Feed, either by its
+// declared content type or by sniffing the first non-whitespace byte
+func isJSONFeed(body []byte, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/feed+json" || mediaType == "application/json") {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// rootElement returns the name of the first element in an XML document
+func rootElement(body []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return xml.Name{}, errors.New("document has no root element")
+			}
+			return xml.Name{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+// parseRSS parses an RSS 2.0 document
+func parseRSS(body []byte) (*types.RSSFeed, error) {
+	var feed types.RSSFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("error parsing XML: %w", err)
+	}
+
+	applyDCDates(feed.Channel.Item)
+	applyDCCreators(feed.Channel.Item)
+
+	// Episodes without their own artwork use the podcast's
+	for i := range feed.Channel.Item {
+		if feed.Channel.Item[i].ITunesImage.Href == "" {
+			feed.Channel.Item[i].ITunesImage = feed.Channel.ITunesImage
+		}
+	}
+
+	return &feed, nil
+}
+
+// parseRDF parses an RSS 1.0 (RDF) document, moving its top-level items
+// into the channel
+func parseRDF(body []byte) (*types.RSSFeed, error) {
+	var rdf types.RDFFeed
+	if err := xml.Unmarshal(body, &rdf); err != nil {
+		return nil, fmt.Errorf("error parsing RDF XML: %w", err)
+	}
+
+	var feed types.RSSFeed
+	feed.Channel.Title = rdf.Channel.Title
+	feed.Channel.Link = rdf.Channel.Link
+	feed.Channel.Description = rdf.Channel.Description
+	feed.Channel.ScheduleHints = rdf.Channel.ScheduleHints
+	feed.Channel.Item = rdf.Item
+
+	applyDCDates(feed.Channel.Item)
+	applyDCCreators(feed.Channel.Item)
+
+	return &feed, nil
+}
+
+// applyDCDates uses an item's dc:date as its publication date when it has no pubDate
+func applyDCDates(items []types.RSSItem) {
+	for i := range items {
+		if items[i].PubDate == "" {
+			items[i].PubDate = strings.TrimSpace(items[i].DCDate)
+		}
+	}
+}
+
+// applyDCCreators adds an item's dc:creator names to its authors and tidies
+// its authors and categories, which may also pick up empty or repeated
+// values from same-named extension elements like itunes:category
+func applyDCCreators(items []types.RSSItem) {
+	for i := range items {
+		items[i].Authors = uniqueStrings(append(items[i].Authors, items[i].DCCreators...))
+		items[i].Categories = uniqueStrings(items[i].Categories)
+	}
+}
+
+// uniqueStrings trims each value and drops empty and repeated ones, keeping
+// the first occurrence's position
+func uniqueStrings(values []string) []string {
+	var unique []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
+
+// parseAtom parses an Atom 1.0 document and converts its entries to RSS items
+func parseAtom(body []byte) (*types.RSSFeed, error) {
+	var atom types.AtomFeed
+	if err := xml.Unmarshal(body, &atom); err != nil {
+		return nil, fmt.Errorf("error parsing Atom XML: %w", err)
+	}
+
+	var feed types.RSSFeed
+	feed.Channel.Title = strings.TrimSpace(atom.Title.String())
+	feed.Channel.Link = types.AlternateLink(atom.Links)
+	feed.Channel.Description = strings.TrimSpace(atom.Subtitle.String())
+
+	for _, entry := range atom.Entries {
+		// Prefer the summary, falling back to the full content
+		description := strings.TrimSpace(entry.Summary.String())
+		if description == "" {
+			description = strings.TrimSpace(entry.Content.String())
+		}
+
+		// Prefer the original publication date over the last update
+		pubDate := strings.TrimSpace(entry.Published)
+		if pubDate == "" {
+			pubDate = strings.TrimSpace(entry.Updated)
+		}
+
+		// Entries without their own authors inherit the feed's
+		people := entry.Authors
+		if len(people) == 0 {
+			people = atom.Authors
+		}
+		var authors []string
+		for _, person := range people {
+			authors = append(authors, person.Name)
+		}
+
+		var categories []string
+		for _, category := range entry.Categories {
+			if category.Label != "" {
+				categories = append(categories, category.Label)
+			} else {
+				categories = append(categories, category.Term)
+			}
+		}
+
+		var enclosures []types.RSSEnclosure
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" && link.Href != "" {
+				enclosures = append(enclosures, types.RSSEnclosure{URL: link.Href, Type: link.Type, Length: link.Length})
+			}
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, types.RSSItem{
+			Title:       strings.TrimSpace(entry.Title.String()),
+			Link:        types.AlternateLink(entry.Links),
+			GUID:        strings.TrimSpace(entry.ID),
+			Description: description,
+			Content:     strings.TrimSpace(entry.Content.String()),
+			PubDate:     pubDate,
+			Authors:     uniqueStrings(authors),
+			Categories:  uniqueStrings(categories),
+			Enclosures:  enclosures,
+		})
+	}
+
+	return &feed, nil
+}
+
+// parseJSONFeed parses a JSON Feed document and converts its items to RSS items
+func parseJSONFeed(body []byte) (*types.RSSFeed, error) {
+	var jsonFeed types.JSONFeed
+	if err := json.Unmarshal(body, &jsonFeed); err != nil {
+		return nil, fmt.Errorf("error parsing JSON Feed: %w", err)
+	}
+
+	var feed types.RSSFeed
+	feed.Channel.Title = jsonFeed.Title
+	feed.Channel.Link = jsonFeed.HomePageURL
+	feed.Channel.Description = jsonFeed.Description
+
+	for _, item := range jsonFeed.Items {
+		// Prefer the summary, falling back to HTML and then plain text content
+		description := item.Summary
+		if description == "" {
+			description = item.ContentHTML
+		}
+		if description == "" {
+			description = item.ContentText
+		}
+
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+
+		var authors []string
+		if item.Author != nil {
+			authors = append(authors, item.Author.Name)
+		}
+		for _, author := range item.Authors {
+			authors = append(authors, author.Name)
+		}
+
+		var enclosures []types.RSSEnclosure
+		duration := ""
+		for _, attachment := range item.Attachments {
+			if attachment.URL == "" {
+				continue
+			}
+			enclosure := types.RSSEnclosure{URL: attachment.URL, Type: attachment.MimeType}
+			if attachment.SizeInBytes > 0 {
+				enclosure.Length = strconv.FormatInt(attachment.SizeInBytes, 10)
+			}
+			if attachment.DurationInSeconds > 0 && duration == "" {
+				duration = strconv.FormatInt(attachment.DurationInSeconds, 10)
+			}
+			enclosures = append(enclosures, enclosure)
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, types.RSSItem{
+			Title:          item.Title,
+			Link:           link,
+			GUID:           item.ID,
+			Description:    description,
+			Content:        content,
+			PubDate:        pubDate,
+			Authors:        uniqueStrings(authors),
+			Categories:     uniqueStrings(item.Tags),
+			Enclosures:     enclosures,
+			ITunesDuration: duration,
+			ITunesImage:    types.ITunesImage{Href: item.Image},
+		})
+	}
+
+	return &feed, nil
+}
@@ -0,0 +1,96 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxRedirects matches the limit of Go's default redirect policy
+const maxRedirects = 10
+
+// isPermanentRedirect reports whether a status code moves a resource for good
+func isPermanentRedirect(statusCode int) bool {
+	return statusCode == http.StatusMovedPermanently || statusCode == http.StatusPermanentRedirect
+}
+
+// MoveFeed points a feed at the URL it permanently redirected to. If another
+// feed already uses that URL, the feed's follows, posts and filter rules are
+// merged into it and the old feed is removed, all in one transaction.
+func (s *Service) MoveFeed(ctx context.Context, feed database.Feed, newURL string) (database.Feed, error) {
+	target, err := s.DB.GetFeedByURL(ctx, newURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// No feed uses the new URL yet, so just update this one
+		moved, err := s.DB.UpdateFeedURL(ctx, database.UpdateFeedURLParams{
+			ID:  feed.ID,
+			Url: newURL,
+		})
+		if err != nil {
+			return database.Feed{}, fmt.Errorf("failed to update feed URL: %w", err)
+		}
+		return moved, nil
+	}
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("failed to look up feed at new URL: %w", err)
+	}
+
+	if target.ID == feed.ID {
+		return feed, nil
+	}
+
+	err = s.DB.InTx(ctx, func(q *database.Queries) error {
+		// Users already following the target keep their existing follow
+		if err := q.MoveFeedFollows(ctx, database.MoveFeedFollowsParams{
+			ToFeedID:   target.ID,
+			FromFeedID: feed.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move feed follows: %w", err)
+		}
+
+		// Carry read marks over to the target's copy of each duplicate post
+		if err := q.MoveDuplicatePostReads(ctx, database.MoveDuplicatePostReadsParams{
+			ToFeedID:   target.ID,
+			FromFeedID: feed.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move read posts: %w", err)
+		}
+
+		// Drop posts the target feed already has so the move can't collide
+		if err := q.DeleteMovedPostDuplicates(ctx, database.DeleteMovedPostDuplicatesParams{
+			FromFeedID: feed.ID,
+			ToFeedID:   target.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to remove duplicate posts: %w", err)
+		}
+
+		if err := q.MovePosts(ctx, database.MovePostsParams{
+			ToFeedID:   target.ID,
+			FromFeedID: feed.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move posts: %w", err)
+		}
+
+		// Per-feed filter rules would otherwise be deleted along with the feed
+		if err := q.MoveFilterRules(ctx, database.MoveFilterRulesParams{
+			ToFeedID:   target.ID,
+			FromFeedID: feed.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to move filter rules: %w", err)
+		}
+
+		// Deleting the old feed also removes any duplicate follows left behind
+		if err := q.DeleteFeed(ctx, feed.ID); err != nil {
+			return fmt.Errorf("failed to delete merged feed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("failed to merge feed into %s: %w", newURL, err)
+	}
+
+	return target, nil
+}
@@ -0,0 +1,138 @@
+package feeds
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/types"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// MinFetchInterval is the shortest interval an adaptive schedule will use
+	MinFetchInterval = 5 * time.Minute
+	// MaxFetchInterval is the longest interval an adaptive schedule will use
+	MaxFetchInterval = 24 * time.Hour
+)
+
+// syndicationPeriods maps sy:updatePeriod values to their durations
+var syndicationPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// AdaptFetchInterval shortens the interval when the last fetch found new
+// posts and lengthens it when it found none, within the adaptive bounds
+func AdaptFetchInterval(current time.Duration, newPosts int) time.Duration {
+	next := current
+	if newPosts > 0 {
+		next = current / 2
+	} else {
+		next = current * 3 / 2
+	}
+
+	if next < MinFetchInterval {
+		return MinFetchInterval
+	}
+	if next > MaxFetchInterval {
+		return MaxFetchInterval
+	}
+	return next
+}
+
+// PublisherMinInterval returns the shortest interval the publisher asks
+// aggregators to respect through <ttl> and sy:updatePeriod/sy:updateFrequency
+func PublisherMinInterval(hints types.ScheduleHints) time.Duration {
+	var minInterval time.Duration
+
+	// <ttl> is the number of minutes the channel may be cached
+	if ttl, err := strconv.Atoi(strings.TrimSpace(hints.TTL)); err == nil && ttl > 0 {
+		minInterval = time.Duration(ttl) * time.Minute
+	}
+
+	// sy:updateFrequency is the number of updates per sy:updatePeriod
+	if period, ok := syndicationPeriods[strings.ToLower(strings.TrimSpace(hints.UpdatePeriod))]; ok {
+		frequency, err := strconv.Atoi(strings.TrimSpace(hints.UpdateFrequency))
+		if err != nil || frequency < 1 {
+			frequency = 1
+		}
+		if interval := period / time.Duration(frequency); interval > minInterval {
+			minInterval = interval
+		}
+	}
+
+	return minInterval
+}
+
+// SkipHours returns the valid UTC hours listed in a channel's <skipHours>
+func SkipHours(hints types.ScheduleHints) []int {
+	var hours []int
+	for _, value := range hints.SkipHours {
+		hour, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || hour < 0 || hour > 23 {
+			continue
+		}
+		hours = append(hours, hour)
+	}
+	return hours
+}
+
+// PublisherSchedule is what a channel's polling hints ask of aggregators
+type PublisherSchedule struct {
+	MinInterval time.Duration
+	SkipHours   []int
+}
+
+// NewPublisherSchedule reads the polling hints of a freshly fetched channel
+func NewPublisherSchedule(hints types.ScheduleHints) PublisherSchedule {
+	return PublisherSchedule{
+		MinInterval: PublisherMinInterval(hints),
+		SkipHours:   SkipHours(hints),
+	}
+}
+
+// StoredPublisherSchedule returns the polling hints stored with a feed at its
+// last full fetch, for fetches that find it unchanged
+func StoredPublisherSchedule(feed database.Feed) PublisherSchedule {
+	schedule := PublisherSchedule{MinInterval: intervalToDuration(feed.PublisherMinInterval)}
+	for _, hour := range feed.SkipHours {
+		schedule.SkipHours = append(schedule.SkipHours, int(hour))
+	}
+	return schedule
+}
+
+// NextFetchDelay returns how long to wait before the next fetch, pushing it
+// past any hours the publisher asked aggregators to skip
+func NextFetchDelay(now time.Time, interval time.Duration, skipHours []int) time.Duration {
+	skip := make(map[int]bool, len(skipHours))
+	for _, hour := range skipHours {
+		skip[hour] = true
+	}
+
+	next := now.UTC().Add(interval)
+	for i := 0; i < 24 && skip[next.Hour()]; i++ {
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	return next.Sub(now.UTC())
+}
+
+// durationToInterval converts a duration to a PostgreSQL interval
+func durationToInterval(d time.Duration) pgtype.Interval {
+	return pgtype.Interval{Microseconds: d.Microseconds(), Valid: true}
+}
+
+// intervalToDuration converts a PostgreSQL interval to a duration, treating
+// a month as 30 days
+func intervalToDuration(interval pgtype.Interval) time.Duration {
+	if !interval.Valid {
+		return 0
+	}
+	days := time.Duration(interval.Days) + time.Duration(interval.Months)*30
+	return time.Duration(interval.Microseconds)*time.Microsecond + days*24*time.Hour
+}
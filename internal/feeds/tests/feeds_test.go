@@ -4,9 +4,15 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
 	"github.com/abahnj/rssagg/internal/feeds"
+	"github.com/abahnj/rssagg/internal/types"
+	"github.com/google/uuid"
 )
 
 func TestFetchFeed(t *testing.T) {
@@ -22,16 +28,27 @@ func TestFetchFeed(t *testing.T) {
 			w.Header().Set("Content-Type", "application/xml")
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
 <channel>
   <title>Test Feed</title>
   <link>https://example.com</link>
   <description>A test RSS feed &amp; more</description>
+  <itunes:image href="https://example.com/show.jpg"/>
   <item>
     <title>Test Item "Quoted"</title>
     <link>https://example.com/item1</link>
+    <guid isPermaLink="false">item-1</guid>
     <description>Test description &lt;b&gt;with HTML&lt;/b&gt;</description>
+    <content:encoded><![CDATA[<p>Full <em>article</em></p>]]></content:encoded>
     <pubDate>Mon, 01 Jan 2024 12:00:00 GMT</pubDate>
+    <dc:creator>Jane Doe</dc:creator>
+    <itunes:author>Jane Doe</itunes:author>
+    <category domain="https://example.com/tags">news</category>
+    <category>releases</category>
+    <itunes:category text="Technology"/>
+    <enclosure url="https://example.com/episode1.mp3" type="audio/mpeg" length="12345"/>
+    <itunes:duration>1:02:03</itunes:duration>
+    <itunes:episode>12</itunes:episode>
   </item>
 </channel>
 </rss>`))
@@ -72,8 +89,222 @@ func TestFetchFeed(t *testing.T) {
 		if item.Description != "Test description <b>with HTML</b>" {
 			t.Errorf("Expected unescaped description, got %s", item.Description)
 		}
+		
+		if item.GUID != "item-1" {
+			t.Errorf("Expected guid, got %s", item.GUID)
+		}
+		
+		if item.Content != "<p>Full <em>article</em></p>" {
+			t.Errorf("Expected content:encoded as content, got %s", item.Content)
+		}
+		
+		if !reflect.DeepEqual(item.Authors, []string{"Jane Doe"}) {
+			t.Errorf("Expected dc:creator as the only author, got %v", item.Authors)
+		}
+		
+		if !reflect.DeepEqual(item.Categories, []string{"news", "releases"}) {
+			t.Errorf("Expected categories without empty values, got %v", item.Categories)
+		}
+		
+		expectedEnclosures := []types.RSSEnclosure{{URL: "https://example.com/episode1.mp3", Type: "audio/mpeg", Length: "12345"}}
+		if !reflect.DeepEqual(item.Enclosures, expectedEnclosures) {
+			t.Errorf("Expected enclosure, got %v", item.Enclosures)
+		}
+		
+		if item.ITunesDuration != "1:02:03" || item.ITunesEpisode != "12" {
+			t.Errorf("Expected iTunes duration and episode, got %q and %q", item.ITunesDuration, item.ITunesEpisode)
+		}
+		
+		if item.ITunesImage.Href != "https://example.com/show.jpg" {
+			t.Errorf("Expected the podcast's image as fallback, got %s", item.ITunesImage.Href)
+		}
 	})
 	
+	t.Run("Keep escaped text in HTML descriptions", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Test Feed</title>
+  <entry>
+    <title>Escaping</title>
+    <link href="https://example.com/escaping"/>
+    <content type="html">&lt;p&gt;Use &amp;lt;script&amp;gt; with care&lt;/p&gt;</content>
+  </entry>
+</feed>`))
+		}))
+		defer server.Close()
+
+		service := &feeds.Service{}
+
+		feed, err := service.FetchFeed(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(feed.Channel.Item) != 1 {
+			t.Fatalf("Expected 1 item, got %d", len(feed.Channel.Item))
+		}
+
+		// The entity is text inside the HTML and must not become a tag
+		expected := "<p>Use &lt;script&gt; with care</p>"
+		if feed.Channel.Item[0].Description != expected {
+			t.Errorf("Expected description %q, got %q", expected, feed.Channel.Item[0].Description)
+		}
+	})
+
+	t.Run("Successfully parse Atom feed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom Test Feed</title>
+  <subtitle>Releases &amp; notes</subtitle>
+  <link rel="self" href="https://example.com/feed.atom"/>
+  <link rel="alternate" href="https://example.com"/>
+  <author><name>Release Bot</name></author>
+  <entry>
+    <id>urn:uuid:60a76c80-d399-11d9-b93c-0003939e0af6</id>
+    <title type="html">v1.0 &lt;b&gt;released&lt;/b&gt;</title>
+    <link rel="edit" href="https://example.com/edit/1"/>
+    <link rel="alternate" type="text/html" href="https://example.com/releases/1"/>
+    <link rel="enclosure" type="application/zip" length="2048" href="https://example.com/releases/1.zip"/>
+    <author><name>Jane Doe</name></author>
+    <category term="release" label="Releases"/>
+    <category term="stable"/>
+    <summary>First release</summary>
+    <published>2024-01-01T12:00:00Z</published>
+    <updated>2024-01-02T12:00:00Z</updated>
+  </entry>
+  <entry>
+    <title>Second entry</title>
+    <link href="https://example.com/releases/2"/>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Full content</p></div></content>
+    <updated>2024-02-01T12:00:00Z</updated>
+  </entry>
+</feed>`))
+		}))
+		defer server.Close()
+
+		service := &feeds.Service{}
+
+		ctx := context.Background()
+		feed, err := service.FetchFeed(ctx, server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if feed.Channel.Title != "Atom Test Feed" {
+			t.Errorf("Expected title to be 'Atom Test Feed', got %s", feed.Channel.Title)
+		}
+
+		if feed.Channel.Link != "https://example.com" {
+			t.Errorf("Expected alternate link, got %s", feed.Channel.Link)
+		}
+
+		if len(feed.Channel.Item) != 2 {
+			t.Fatalf("Expected 2 items, got %d", len(feed.Channel.Item))
+		}
+
+		first := feed.Channel.Item[0]
+		if first.Title != "v1.0 <b>released</b>" {
+			t.Errorf("Expected unescaped title, got %s", first.Title)
+		}
+		if first.Link != "https://example.com/releases/1" {
+			t.Errorf("Expected alternate link, got %s", first.Link)
+		}
+		if first.Description != "First release" {
+			t.Errorf("Expected summary as description, got %s", first.Description)
+		}
+		if first.PubDate != "2024-01-01T12:00:00Z" {
+			t.Errorf("Expected published date, got %s", first.PubDate)
+		}
+		if first.GUID != "urn:uuid:60a76c80-d399-11d9-b93c-0003939e0af6" {
+			t.Errorf("Expected entry id as guid, got %s", first.GUID)
+		}
+		if !reflect.DeepEqual(first.Authors, []string{"Jane Doe"}) {
+			t.Errorf("Expected entry author, got %v", first.Authors)
+		}
+		if !reflect.DeepEqual(first.Categories, []string{"Releases", "stable"}) {
+			t.Errorf("Expected category labels falling back to terms, got %v", first.Categories)
+		}
+		expectedEnclosures := []types.RSSEnclosure{{URL: "https://example.com/releases/1.zip", Type: "application/zip", Length: "2048"}}
+		if !reflect.DeepEqual(first.Enclosures, expectedEnclosures) {
+			t.Errorf("Expected enclosure link, got %v", first.Enclosures)
+		}
+
+		second := feed.Channel.Item[1]
+		if second.Link != "https://example.com/releases/2" {
+			t.Errorf("Expected link without rel, got %s", second.Link)
+		}
+		if !strings.Contains(second.Description, "<p>Full content</p>") {
+			t.Errorf("Expected XHTML content as description, got %s", second.Description)
+		}
+		if second.PubDate != "2024-02-01T12:00:00Z" {
+			t.Errorf("Expected updated date as fallback, got %s", second.PubDate)
+		}
+		if !strings.Contains(second.Content, "<p>Full content</p>") {
+			t.Errorf("Expected XHTML content as content, got %s", second.Content)
+		}
+		if !reflect.DeepEqual(second.Authors, []string{"Release Bot"}) {
+			t.Errorf("Expected feed author as fallback, got %v", second.Authors)
+		}
+	})
+
+	t.Run("Successfully parse RSS 1.0 feed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/rdf+xml")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+         xmlns:dc="http://purl.org/dc/elements/1.1/"
+         xmlns="http://purl.org/rss/1.0/">
+  <channel rdf:about="https://example.edu/">
+    <title>RDF Test Feed</title>
+    <link>https://example.edu/</link>
+    <description>An RSS 1.0 feed</description>
+    <items>
+      <rdf:Seq>
+        <rdf:li rdf:resource="https://example.edu/papers/1"/>
+      </rdf:Seq>
+    </items>
+  </channel>
+  <item rdf:about="https://example.edu/papers/1">
+    <title>First paper</title>
+    <link>https://example.edu/papers/1</link>
+    <description>Abstract</description>
+    <dc:date>2024-01-15T10:30Z</dc:date>
+  </item>
+</rdf:RDF>`))
+		}))
+		defer server.Close()
+
+		service := &feeds.Service{}
+
+		ctx := context.Background()
+		feed, err := service.FetchFeed(ctx, server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if feed.Channel.Title != "RDF Test Feed" {
+			t.Errorf("Expected title to be 'RDF Test Feed', got %s", feed.Channel.Title)
+		}
+
+		if len(feed.Channel.Item) != 1 {
+			t.Fatalf("Expected 1 item, got %d", len(feed.Channel.Item))
+		}
+
+		item := feed.Channel.Item[0]
+		if item.Link != "https://example.edu/papers/1" {
+			t.Errorf("Expected item link, got %s", item.Link)
+		}
+		if item.PubDate != "2024-01-15T10:30Z" {
+			t.Errorf("Expected dc:date as publication date, got %s", item.PubDate)
+		}
+	})
+
 	t.Run("Handle error status code", func(t *testing.T) {
 		// Create a test server that returns an error
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -93,4 +324,146 @@ func TestFetchFeed(t *testing.T) {
 			t.Fatalf("Expected error for status 404, got nil")
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestFetchFeedConditional(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Mon, 01 Jan 2024 12:00:00 GMT"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/xml")
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<rss version="2.0"><channel><title>Cached Feed</title><ttl>45</ttl><skipHours><hour>1</hour><hour>2</hour></skipHours></channel></rss>`))
+	}))
+	defer server.Close()
+
+	service := &feeds.Service{}
+	ctx := context.Background()
+
+	t.Run("Return validators on first fetch", func(t *testing.T) {
+		result, err := service.FetchFeedConditional(ctx, server.URL, "", "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if result.NotModified {
+			t.Error("Expected a full response on first fetch")
+		}
+		if result.Feed == nil || result.Feed.Channel.Title != "Cached Feed" {
+			t.Errorf("Expected parsed feed, got %+v", result.Feed)
+		}
+		if result.ETag != etag {
+			t.Errorf("Expected ETag %s, got %s", etag, result.ETag)
+		}
+		if result.Feed.Channel.TTL != "45" || len(result.Feed.Channel.SkipHours) != 2 {
+			t.Errorf("Expected schedule hints to be parsed, got %+v", result.Feed.Channel.ScheduleHints)
+		}
+		if result.LastModified != lastModified {
+			t.Errorf("Expected Last-Modified %s, got %s", lastModified, result.LastModified)
+		}
+	})
+
+	t.Run("Handle 304 Not Modified", func(t *testing.T) {
+		result, err := service.FetchFeedConditional(ctx, server.URL, etag, lastModified)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !result.NotModified {
+			t.Error("Expected NotModified to be true")
+		}
+		if result.Feed != nil {
+			t.Error("Expected no feed for a 304 response")
+		}
+		if result.ETag != etag || result.LastModified != lastModified {
+			t.Errorf("Expected validators to be preserved, got %q and %q", result.ETag, result.LastModified)
+		}
+	})
+}
+
+func TestFetchFeedRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<rss version="2.0"><channel><title>Moved Feed</title></channel></rss>`))
+	})
+	mux.Handle("/moved", http.RedirectHandler("/feed", http.StatusMovedPermanently))
+	mux.Handle("/moved-again", http.RedirectHandler("/moved", http.StatusPermanentRedirect))
+	mux.Handle("/temporary", http.RedirectHandler("/feed", http.StatusFound))
+	mux.Handle("/mixed", http.RedirectHandler("/temporary", http.StatusMovedPermanently))
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	service := &feeds.Service{}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "Permanent redirect", path: "/moved", expected: server.URL + "/feed"},
+		{name: "Chain of permanent redirects", path: "/moved-again", expected: server.URL + "/feed"},
+		{name: "Temporary redirect", path: "/temporary", expected: ""},
+		{name: "Permanent then temporary redirect", path: "/mixed", expected: server.URL + "/temporary"},
+		{name: "No redirect", path: "/feed", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.FetchFeedConditional(ctx, server.URL+tt.path, "", "")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if result.Feed.Channel.Title != "Moved Feed" {
+				t.Errorf("Expected feed to be fetched, got title %s", result.Feed.Channel.Title)
+			}
+			if result.PermanentURL != tt.expected {
+				t.Errorf("Expected permanent URL %q, got %q", tt.expected, result.PermanentURL)
+			}
+		})
+	}
+}
+
+func TestAddFeed(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Go Blog", Url: "https://go.dev/blog/feed.atom"}
+
+	t.Run("New feed", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"CreateFeed": {dbtest.FeedRow(feed)}}}
+		service := feeds.NewService(dbtest.New(db))
+
+		got, created, err := service.AddFeed(context.Background(), feed.Name, feed.Url, uuid.New())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !created || got.ID != feed.ID {
+			t.Errorf("Expected the feed to be created, got created=%v %+v", created, got)
+		}
+	})
+
+	t.Run("Existing feed", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"GetFeedByURL": {dbtest.FeedRow(feed)}}}
+		service := feeds.NewService(dbtest.New(db))
+
+		// The existing feed is returned even when added under another name
+		got, created, err := service.AddFeed(context.Background(), "Another name", feed.Url, uuid.New())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if created || got.ID != feed.ID {
+			t.Errorf("Expected the existing feed, got created=%v %+v", created, got)
+		}
+		if !reflect.DeepEqual(db.Queries, []string{"GetFeedByURL"}) {
+			t.Errorf("Expected no feed to be created, got queries %v", db.Queries)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/feeds"
+	"github.com/google/uuid"
+)
+
+func TestSetFetchFullArticle(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Go Blog", Url: "https://go.dev/blog/feed.atom"}
+
+	t.Run("Followers can turn it on", func(t *testing.T) {
+		db := &dbtest.DB{
+			Rows: map[string][][]any{"GetFeedByURL": {dbtest.FeedRow(feed)}},
+			Tags: map[string]string{"SetFeedFetchFullArticle": "UPDATE 1"},
+		}
+		service := feeds.NewService(dbtest.New(db))
+
+		updated, err := service.SetFetchFullArticle(context.Background(), feed.Url, uuid.New(), true)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !updated.FetchFullArticle {
+			t.Error("Expected full articles to be turned on")
+		}
+	})
+
+	t.Run("Other users can't change it", func(t *testing.T) {
+		db := &dbtest.DB{
+			Rows: map[string][][]any{"GetFeedByURL": {dbtest.FeedRow(feed)}},
+			Tags: map[string]string{"SetFeedFetchFullArticle": "UPDATE 0"},
+		}
+		service := feeds.NewService(dbtest.New(db))
+
+		_, err := service.SetFetchFullArticle(context.Background(), feed.Url, uuid.New(), true)
+		if !errors.Is(err, feeds.ErrNotFollowing) {
+			t.Errorf("Expected ErrNotFollowing, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/feeds"
+)
+
+func TestFailureBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		failures int
+		expected time.Duration
+	}{
+		{name: "First failure keeps the interval", interval: time.Hour, failures: 1, expected: time.Hour},
+		{name: "Doubles per failure", interval: time.Hour, failures: 4, expected: 8 * time.Hour},
+		{name: "Uses the minimum interval", interval: 0, failures: 2, expected: 2 * feeds.MinFetchInterval},
+		{name: "Caps the backoff", interval: time.Hour, failures: 30, expected: feeds.MaxFailureBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := feeds.FailureBackoff(tt.interval, tt.failures)
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/feeds"
+	"github.com/abahnj/rssagg/internal/types"
+)
+
+func TestFetchJSONFeed(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "jsonfeed.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{name: "Detect by content type", contentType: "application/feed+json; charset=utf-8"},
+		{name: "Detect by body sniffing", contentType: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(http.StatusOK)
+				w.Write(fixture)
+			}))
+			defer server.Close()
+
+			service := &feeds.Service{}
+
+			ctx := context.Background()
+			feed, err := service.FetchFeed(ctx, server.URL)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if feed.Channel.Title != "JSON Test Feed" {
+				t.Errorf("Expected title to be 'JSON Test Feed', got %s", feed.Channel.Title)
+			}
+
+			if len(feed.Channel.Item) != 2 {
+				t.Fatalf("Expected 2 items, got %d", len(feed.Channel.Item))
+			}
+
+			first := feed.Channel.Item[0]
+			if first.Link != "https://example.org/second-item" {
+				t.Errorf("Expected item URL, got %s", first.Link)
+			}
+			if first.Description != "A short summary" {
+				t.Errorf("Expected summary as description, got %s", first.Description)
+			}
+			if first.PubDate != "2024-03-02T09:30:00-05:00" {
+				t.Errorf("Expected date_published, got %s", first.PubDate)
+			}
+			if first.GUID != "2" {
+				t.Errorf("Expected item id as guid, got %s", first.GUID)
+			}
+			if first.Content != "<p>Full <em>HTML</em> content</p>" {
+				t.Errorf("Expected content_html as content, got %s", first.Content)
+			}
+			if !reflect.DeepEqual(first.Authors, []string{"Jane Doe"}) {
+				t.Errorf("Expected authors, got %v", first.Authors)
+			}
+			if !reflect.DeepEqual(first.Categories, []string{"podcast", "audio"}) {
+				t.Errorf("Expected tags as categories, got %v", first.Categories)
+			}
+			expectedEnclosures := []types.RSSEnclosure{{URL: "https://example.org/second-item.m4a", Type: "audio/x-m4a", Length: "89970236"}}
+			if !reflect.DeepEqual(first.Enclosures, expectedEnclosures) {
+				t.Errorf("Expected attachment as enclosure, got %v", first.Enclosures)
+			}
+
+			second := feed.Channel.Item[1]
+			if second.Description != "<p>Hello, world!</p>" {
+				t.Errorf("Expected content_html as description, got %s", second.Description)
+			}
+			if second.PubDate != "2024-03-01T08:00:00Z" {
+				t.Errorf("Expected date_modified as fallback, got %s", second.PubDate)
+			}
+			if !reflect.DeepEqual(second.Authors, []string{"John Doe"}) {
+				t.Errorf("Expected JSON Feed 1.0 author, got %v", second.Authors)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/feeds"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestMoveFeed(t *testing.T) {
+	feed := database.Feed{ID: uuid.New(), Name: "Old", Url: "http://old.example.com/feed"}
+	target := database.Feed{ID: uuid.New(), Name: "New", Url: "https://new.example.com/feed",
+		FetchInterval: pgtype.Interval{Microseconds: 3600e6, Valid: true}}
+
+	t.Run("Merges into an existing feed in one transaction", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"GetFeedByURL": {dbtest.FeedRow(target)}}}
+		service := feeds.NewService(dbtest.New(db))
+
+		moved, err := service.MoveFeed(context.Background(), feed, target.Url)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if moved.ID != target.ID {
+			t.Errorf("Expected the target feed, got %+v", moved)
+		}
+
+		expected := []string{"GetFeedByURL", "tx:MoveFeedFollows", "tx:MoveDuplicatePostReads", "tx:DeleteMovedPostDuplicates", "tx:MovePosts", "tx:MoveFilterRules", "tx:DeleteFeed"}
+		if !reflect.DeepEqual(db.Queries, expected) {
+			t.Errorf("Expected queries %v, got %v", expected, db.Queries)
+		}
+		if db.Tx == nil || !db.Tx.Committed {
+			t.Error("Expected the merge to be committed")
+		}
+	})
+
+	t.Run("Moves per-feed filter rules to the target", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"GetFeedByURL": {dbtest.FeedRow(target)}}}
+		service := feeds.NewService(dbtest.New(db))
+
+		if _, err := service.MoveFeed(context.Background(), feed, target.Url); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// Rules must be repointed before the cascade on DeleteFeed removes them
+		expected := []any{target.ID, feed.ID}
+		if args := db.LastArgs("MoveFilterRules"); !reflect.DeepEqual(args, expected) {
+			t.Errorf("Expected filter rules moved with %v, got %v", expected, args)
+		}
+	})
+
+	t.Run("Rolls back a failed merge", func(t *testing.T) {
+		db := &dbtest.DB{
+			Rows: map[string][][]any{"GetFeedByURL": {dbtest.FeedRow(target)}},
+			Errs: map[string]error{"MovePosts": errors.New("connection reset")},
+		}
+		service := feeds.NewService(dbtest.New(db))
+
+		if _, err := service.MoveFeed(context.Background(), feed, target.Url); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if db.Tx == nil || db.Tx.Committed || !db.Tx.RolledBack {
+			t.Error("Expected the merge to be rolled back")
+		}
+		for _, query := range db.Queries {
+			if query == "tx:DeleteFeed" {
+				t.Error("Expected the old feed not to be deleted")
+			}
+		}
+	})
+
+	t.Run("Updates the URL when no feed uses it", func(t *testing.T) {
+		updated := feed
+		updated.Url = target.Url
+		db := &dbtest.DB{Rows: map[string][][]any{"UpdateFeedURL": {dbtest.FeedRow(updated)}}}
+		service := feeds.NewService(dbtest.New(db))
+
+		moved, err := service.MoveFeed(context.Background(), feed, target.Url)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if moved.ID != feed.ID || moved.Url != target.Url {
+			t.Errorf("Expected the feed at its new URL, got %+v", moved)
+		}
+		if db.Tx != nil {
+			t.Error("Expected no transaction for a plain URL update")
+		}
+	})
+
+	t.Run("Lookup errors are returned", func(t *testing.T) {
+		db := &dbtest.DB{Errs: map[string]error{"GetFeedByURL": errors.New("connection reset")}}
+		service := feeds.NewService(dbtest.New(db))
+
+		if _, err := service.MoveFeed(context.Background(), feed, target.Url); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if !reflect.DeepEqual(db.Queries, []string{"GetFeedByURL"}) {
+			t.Errorf("Expected the feed to be left alone, got queries %v", db.Queries)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/feeds"
+	"github.com/abahnj/rssagg/internal/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestAdaptFetchInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  time.Duration
+		newPosts int
+		expected time.Duration
+	}{
+		{name: "Shorten when new posts arrive", current: time.Hour, newPosts: 3, expected: 30 * time.Minute},
+		{name: "Lengthen when nothing is new", current: time.Hour, newPosts: 0, expected: 90 * time.Minute},
+		{name: "Clamp to minimum", current: 6 * time.Minute, newPosts: 1, expected: feeds.MinFetchInterval},
+		{name: "Clamp to maximum", current: 20 * time.Hour, newPosts: 0, expected: feeds.MaxFetchInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := feeds.AdaptFetchInterval(tt.current, tt.newPosts)
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPublisherMinInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		hints    types.ScheduleHints
+		expected time.Duration
+	}{
+		{name: "No hints", hints: types.ScheduleHints{}, expected: 0},
+		{name: "TTL in minutes", hints: types.ScheduleHints{TTL: " 90 "}, expected: 90 * time.Minute},
+		{name: "Update period", hints: types.ScheduleHints{UpdatePeriod: "daily", UpdateFrequency: "4"}, expected: 6 * time.Hour},
+		{name: "Longest hint wins", hints: types.ScheduleHints{TTL: "60", UpdatePeriod: "daily"}, expected: 24 * time.Hour},
+		{name: "Invalid TTL is ignored", hints: types.ScheduleHints{TTL: "soon"}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := feeds.PublisherMinInterval(tt.hints)
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNextFetchDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	t.Run("No skip hours", func(t *testing.T) {
+		got := feeds.NextFetchDelay(now, time.Hour, nil)
+		if got != time.Hour {
+			t.Errorf("Expected 1h, got %s", got)
+		}
+	})
+
+	t.Run("Skip past consecutive hours", func(t *testing.T) {
+		// 11:30 and 12:00 are both skipped, so the fetch moves to 13:00
+		skipHours := feeds.SkipHours(types.ScheduleHints{SkipHours: []string{"11", "12", "99"}})
+		got := feeds.NextFetchDelay(now, time.Hour, skipHours)
+		if got != 150*time.Minute {
+			t.Errorf("Expected 2h30m, got %s", got)
+		}
+	})
+}
+
+func TestScheduleNextFetchKeepsPublisherHints(t *testing.T) {
+	hints := types.ScheduleHints{TTL: "120", SkipHours: []string{"3", "4"}}
+	feed := database.Feed{ID: uuid.New(), FetchInterval: pgtype.Interval{Microseconds: int64(10 * time.Minute / time.Microsecond), Valid: true}}
+
+	// A full fetch stores the channel's hints with the feed
+	db := &dbtest.DB{}
+	service := feeds.NewService(dbtest.New(db))
+	if err := service.ScheduleNextFetch(context.Background(), feed, 1, feeds.NewPublisherSchedule(hints)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	args := db.LastArgs("ScheduleFeedFetch")
+	feed.PublisherMinInterval = args[2].(pgtype.Interval)
+	feed.SkipHours = args[3].([]int32)
+
+	// An unchanged feed carries no hints, so the stored ones still apply
+	schedule := feeds.StoredPublisherSchedule(feed)
+	if schedule.MinInterval != 2*time.Hour || !reflect.DeepEqual(schedule.SkipHours, []int{3, 4}) {
+		t.Fatalf("Expected the stored hints to round-trip, got %+v", schedule)
+	}
+	if err := service.ScheduleNextFetch(context.Background(), feed, 0, schedule); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if interval := db.LastArgs("ScheduleFeedFetch")[0].(pgtype.Interval); interval.Microseconds != int64(2*time.Hour/time.Microsecond) {
+		t.Errorf("Expected the publisher's 2h minimum interval, got %dus", interval.Microseconds)
+	}
+}
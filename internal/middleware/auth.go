@@ -7,6 +7,7 @@ import (
 
 	"github.com/abahnj/rssagg/internal/cli"
 	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/users"
 )
 
 // LoggedInHandlerFunc is a handler function that requires a logged-in user
@@ -23,11 +24,15 @@ func MiddlewareLoggedIn(handler LoggedInHandlerFunc) cli.HandlerFunc {
 
 		ctx := context.Background()
 		
-		// Get the current user from the database
-		user, err := s.Db.GetUserByName(ctx, s.Config.CurrentUserName)
+		// Get the current user from their cached session token
+		service := users.NewService(*s.Db)
+		user, err := service.UserForSession(ctx, s.Config.SessionToken)
 		if err != nil {
 			return fmt.Errorf("failed to get current user: %w", err)
 		}
+		if user.Name != s.Config.CurrentUserName {
+			return users.ErrInvalidSession
+		}
 
 		// Call the wrapped handler with the authenticated user
 		return handler(s, cmd, user)
@@ -1,11 +1,102 @@
 package tests
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
+	"github.com/abahnj/rssagg/internal/cli"
+	"github.com/abahnj/rssagg/internal/config"
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/middleware"
+	"github.com/abahnj/rssagg/internal/users"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+func userRow(user database.User) []any {
+	return []any{user.ID, user.Name, user.CreatedAt, user.UpdatedAt, user.ApiKeyHash, user.PasswordHash}
+}
+
 func TestMiddlewareLoggedIn(t *testing.T) {
-	// Skip tests for now to focus on refactoring
-	t.Skip("Skipping until middleware refactoring complete")
-}
\ No newline at end of file
+	alice := database.User{ID: uuid.New(), Name: "alice", PasswordHash: pgtype.Text{String: "hash", Valid: true}}
+
+	tests := []struct {
+		name     string
+		config   *config.Config
+		rows     [][]any
+		wantErr  error
+		wantUser bool
+	}{
+		{
+			name:   "Not logged in",
+			config: &config.Config{},
+		},
+		{
+			name:    "Missing token",
+			config:  &config.Config{CurrentUserName: "alice"},
+			wantErr: users.ErrInvalidSession,
+		},
+		{
+			// The query only matches sessions that haven't expired
+			name:    "Expired token",
+			config:  &config.Config{CurrentUserName: "alice", SessionToken: "expired"},
+			wantErr: users.ErrInvalidSession,
+		},
+		{
+			name:    "Session belongs to another user",
+			config:  &config.Config{CurrentUserName: "bob", SessionToken: "token"},
+			rows:    [][]any{userRow(alice)},
+			wantErr: users.ErrInvalidSession,
+		},
+		{
+			name:     "Valid session",
+			config:   &config.Config{CurrentUserName: "alice", SessionToken: "token"},
+			rows:     [][]any{userRow(alice)},
+			wantUser: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &dbtest.DB{Rows: map[string][][]any{"GetUserBySessionTokenHash": tt.rows}}
+			queries := dbtest.New(db)
+			state := &cli.State{Config: tt.config, Db: &queries}
+
+			called := false
+			handler := middleware.MiddlewareLoggedIn(func(s *cli.State, cmd cli.Command, user database.User) error {
+				called = true
+				if user.ID != alice.ID {
+					t.Errorf("Expected alice, got %+v", user)
+				}
+				return nil
+			})
+
+			err := handler(state, cli.Command{Name: "following"})
+			if called != tt.wantUser {
+				t.Errorf("Expected handler called to be %v", tt.wantUser)
+			}
+			if tt.wantUser {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.config.SessionToken != "" {
+				expected := []any{users.HashToken(tt.config.SessionToken)}
+				if args := db.LastArgs("GetUserBySessionTokenHash"); !reflect.DeepEqual(args, expected) {
+					t.Errorf("Expected the session looked up by token hash, got %v", args)
+				}
+			} else if db.Count("GetUserBySessionTokenHash") != 0 {
+				t.Error("Expected no session lookup without a token")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Document represents an OPML 1.0 or 2.0 document
+type Document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+// Head holds the document's metadata
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// Body holds the document's top-level outlines
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is either a subscription (with an xmlUrl) or a category
+// grouping nested outlines
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a subscription found in an OPML document
+type Feed struct {
+	Title    string
+	URL      string
+	Category string
+}
+
+// Parse reads an OPML document
+func Parse(r io.Reader) (*Document, error) {
+	var doc Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error parsing OPML: %w", err)
+	}
+	return &doc, nil
+}
+
+// Feeds returns every subscription in the document, flattening nested
+// category outlines into a slash-separated category path
+func (d *Document) Feeds() []Feed {
+	var feeds []Feed
+	collectFeeds(d.Body.Outlines, "", &feeds)
+	return feeds
+}
+
+// NewDocument builds an OPML 2.0 document listing the given subscriptions
+func NewDocument(title string, feeds []Feed, created time.Time) *Document {
+	doc := &Document{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: created.UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, feed := range feeds {
+		doc.Body.Outlines = append(doc.Body.Outlines, Outline{
+			Text:   feed.Title,
+			Title:  feed.Title,
+			Type:   "rss",
+			XMLURL: feed.URL,
+		})
+	}
+
+	return doc
+}
+
+// Write encodes the document as indented XML with an XML declaration
+func (d *Document) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing OPML: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return fmt.Errorf("error writing OPML: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// collectFeeds walks outlines depth-first, appending subscriptions to feeds
+func collectFeeds(outlines []Outline, category string, feeds *[]Feed) {
+	for _, outline := range outlines {
+		title := strings.TrimSpace(outline.Title)
+		if title == "" {
+			title = strings.TrimSpace(outline.Text)
+		}
+
+		if url := strings.TrimSpace(outline.XMLURL); url != "" {
+			*feeds = append(*feeds, Feed{
+				Title:    title,
+				URL:      url,
+				Category: category,
+			})
+		}
+
+		if len(outline.Outlines) > 0 {
+			childCategory := title
+			if category != "" {
+				childCategory = category + "/" + title
+			}
+			collectFeeds(outline.Outlines, childCategory, feeds)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/opml"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Flatten nested outlines", func(t *testing.T) {
+		doc, err := opml.Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Top Level" type="rss" xmlUrl="https://example.com/top.xml"/>
+    <outline text="Tech">
+      <outline text="Go Blog" title="The Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+      <outline text="Databases">
+        <outline text="Postgres" type="rss" xmlUrl=" https://www.postgresql.org/news.rss "/>
+      </outline>
+    </outline>
+    <outline text="Empty category"/>
+  </body>
+</opml>`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if doc.Head.Title != "Subscriptions" {
+			t.Errorf("Expected head title, got %s", doc.Head.Title)
+		}
+
+		feeds := doc.Feeds()
+		expected := []opml.Feed{
+			{Title: "Top Level", URL: "https://example.com/top.xml"},
+			{Title: "The Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Tech"},
+			{Title: "Postgres", URL: "https://www.postgresql.org/news.rss", Category: "Tech/Databases"},
+		}
+
+		if len(feeds) != len(expected) {
+			t.Fatalf("Expected %d feeds, got %d", len(expected), len(feeds))
+		}
+		for i := range expected {
+			if feeds[i] != expected[i] {
+				t.Errorf("Expected feed %d to be %+v, got %+v", i, expected[i], feeds[i])
+			}
+		}
+	})
+
+	t.Run("Reject malformed documents", func(t *testing.T) {
+		_, err := opml.Parse(strings.NewReader(`<opml><body><outline`))
+		if err == nil {
+			t.Fatal("Expected error for malformed OPML, got nil")
+		}
+	})
+}
+
+func TestWrite(t *testing.T) {
+	feeds := []opml.Feed{
+		{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom"},
+		{Title: "News & Notes", URL: "https://example.com/feed?a=1&b=2"},
+	}
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := opml.NewDocument("rssagg subscriptions", feeds, created).Write(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	output := buf.String()
+	if !strings.HasPrefix(output, `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("Expected XML declaration, got %s", output)
+	}
+	if !strings.Contains(output, `<opml version="2.0">`) {
+		t.Errorf("Expected OPML 2.0 root element, got %s", output)
+	}
+
+	// The written document must round-trip through the parser
+	doc, err := opml.Parse(&buf)
+	if err != nil {
+		t.Fatalf("Expected written OPML to parse, got %v", err)
+	}
+
+	if doc.Head.DateCreated != "Mon, 01 Jan 2024 12:00:00 +0000" {
+		t.Errorf("Expected RFC 822 creation date, got %s", doc.Head.DateCreated)
+	}
+
+	parsed := doc.Feeds()
+	if len(parsed) != len(feeds) {
+		t.Fatalf("Expected %d feeds, got %d", len(feeds), len(parsed))
+	}
+	for i := range feeds {
+		if parsed[i] != feeds[i] {
+			t.Errorf("Expected feed %d to be %+v, got %+v", i, feeds[i], parsed[i])
+		}
+	}
+}
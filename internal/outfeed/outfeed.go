@@ -0,0 +1,247 @@
+// Package outfeed renders a list of posts as an RSS 2.0 or Atom 1.0 feed
+package outfeed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format is the syndication format a feed is written in
+type Format string
+
+const (
+	FormatRSS  Format = "rss"
+	FormatAtom Format = "atom"
+)
+
+// generator identifies rssagg in the feeds it writes
+const generator = "rssagg"
+
+// homepage is the channel link of RSS feeds written without a SelfURL, since
+// RSS 2.0 requires every channel to have one
+const homepage = "https://github.com/abahnj/rssagg"
+
+// ParseFormat validates a --format value
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case FormatRSS, FormatAtom:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid feed format %q (expected rss or atom)", value)
+	}
+}
+
+// MediaType returns the MIME type for a format
+func (f Format) MediaType() string {
+	if f == FormatAtom {
+		return "application/atom+xml"
+	}
+	return "application/rss+xml"
+}
+
+// ContentType returns the Content-Type header for a format
+func (f Format) ContentType() string {
+	return f.MediaType() + "; charset=utf-8"
+}
+
+// Feed is the format-independent content of an output feed
+type Feed struct {
+	// ID is a permanent, unique identifier such as a urn:uuid: URI
+	ID          string
+	Title       string
+	Description string
+	// SelfURL is where the feed itself can be fetched, if known
+	SelfURL string
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is a single entry in an output feed
+type Item struct {
+	ID          string
+	Title       string
+	URL         string
+	Description string // HTML
+	Published   time.Time
+	// Source is the name of the feed the item came from
+	Source string
+}
+
+// rssDocument is an RSS 2.0 document
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title string `xml:"title"`
+	// AtomLink comes before Link because parsers that ignore namespaces
+	// would otherwise overwrite the channel link with its empty content
+	AtomLink      *atomLink `xml:"atom:link,omitempty"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate,omitempty"`
+	Description string  `xml:"description,omitempty"`
+	Category    string  `xml:"category,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// atomDocument is an Atom 1.0 feed
+type atomDocument struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	Updated   string      `xml:"updated"`
+	Author    atomAuthor  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Links     []atomLink  `xml:"link"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Link      atomLink    `xml:"link"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published,omitempty"`
+	Summary   *atomText   `xml:"summary,omitempty"`
+	Source    *atomSource `xml:"source,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomSource struct {
+	Title string `xml:"title"`
+}
+
+// Write encodes the feed in the given format as indented XML with an XML declaration
+func (f *Feed) Write(w io.Writer, format Format) error {
+	var doc any
+	if format == FormatAtom {
+		doc = f.atom()
+	} else {
+		doc = f.rss()
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// rss converts the feed to an RSS 2.0 document
+func (f *Feed) rss() *rssDocument {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          homepage,
+		Description:   f.Description,
+		LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+		Generator:     generator,
+	}
+	if f.SelfURL != "" {
+		channel.Link = f.SelfURL
+		channel.AtomLink = &atomLink{Href: f.SelfURL, Rel: "self", Type: FormatRSS.MediaType()}
+	}
+
+	for _, item := range f.Items {
+		rssItem := rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssGUID{Value: item.ID},
+			Description: item.Description,
+			Category:    item.Source,
+		}
+		if !item.Published.IsZero() {
+			rssItem.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, rssItem)
+	}
+
+	return &rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: channel,
+	}
+}
+
+// atom converts the feed to an Atom 1.0 document
+func (f *Feed) atom() *atomDocument {
+	doc := &atomDocument{
+		ID:        f.ID,
+		Title:     f.Title,
+		Subtitle:  f.Description,
+		Updated:   f.Updated.UTC().Format(time.RFC3339),
+		Author:    atomAuthor{Name: generator},
+		Generator: generator,
+	}
+	if f.SelfURL != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.SelfURL, Rel: "self", Type: FormatAtom.MediaType()})
+	}
+
+	for _, item := range f.Items {
+		// Atom requires an updated time; fall back to the feed's for undated items
+		updated := item.Published
+		if updated.IsZero() {
+			updated = f.Updated
+		}
+
+		entry := atomEntry{
+			ID:      item.ID,
+			Title:   item.Title,
+			Link:    atomLink{Href: item.URL, Rel: "alternate"},
+			Updated: updated.UTC().Format(time.RFC3339),
+		}
+		if !item.Published.IsZero() {
+			entry.Published = item.Published.UTC().Format(time.RFC3339)
+		}
+		if item.Description != "" {
+			entry.Summary = &atomText{Type: "html", Value: item.Description}
+		}
+		if item.Source != "" {
+			entry.Source = &atomSource{Title: item.Source}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return doc
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/outfeed"
+	"github.com/abahnj/rssagg/internal/types"
+)
+
+func sampleFeed() *outfeed.Feed {
+	return &outfeed.Feed{
+		ID:          "urn:uuid:6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a",
+		Title:       "alice's feeds",
+		Description: "Posts from the feeds alice follows",
+		SelfURL:     "https://rssagg.example.com/users/6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a/feed.xml",
+		Updated:     time.Date(2024, 1, 31, 8, 15, 0, 0, time.UTC),
+		Items: []outfeed.Item{
+			{
+				ID:          "urn:uuid:0b6e7c43-2f55-4d7a-8f0e-8a3c1f2b9d10",
+				Title:       "Generics & you",
+				URL:         "https://go.dev/blog/generics",
+				Description: "<p>Type parameters</p>",
+				Published:   time.Date(2024, 1, 31, 8, 15, 0, 0, time.UTC),
+				Source:      "The Go Blog",
+			},
+			{
+				ID:    "urn:uuid:3c1d7e9f-5a2b-4c8d-9e0f-1a2b3c4d5e6f",
+				Title: "Undated",
+				URL:   "https://example.com/undated",
+			},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, value := range []string{"rss", "atom", "ATOM"} {
+		if _, err := outfeed.ParseFormat(value); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", value, err)
+		}
+	}
+	if _, err := outfeed.ParseFormat("json"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleFeed().Write(&buf, outfeed.FormatRSS); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "<?xml") || !strings.Contains(buf.String(), `<rss version="2.0"`) {
+		t.Errorf("Expected an RSS 2.0 document, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `<guid isPermaLink="false">urn:uuid:0b6e7c43-2f55-4d7a-8f0e-8a3c1f2b9d10</guid>`) {
+		t.Errorf("Expected a non-permalink guid, got:\n%s", buf.String())
+	}
+
+	// The output must parse back with the types the aggregator reads feeds with
+	var feed types.RSSFeed
+	if err := xml.Unmarshal(buf.Bytes(), &feed); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if feed.Channel.Title != "alice's feeds" || feed.Channel.Link != sampleFeed().SelfURL {
+		t.Errorf("Unexpected channel: %q %q", feed.Channel.Title, feed.Channel.Link)
+	}
+	if len(feed.Channel.Item) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(feed.Channel.Item))
+	}
+
+	item := feed.Channel.Item[0]
+	if item.Title != "Generics & you" || item.Link != "https://go.dev/blog/generics" || item.Description != "<p>Type parameters</p>" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if item.PubDate != "Wed, 31 Jan 2024 08:15:00 +0000" {
+		t.Errorf("Expected RFC 1123 publish date, got %q", item.PubDate)
+	}
+	if feed.Channel.Item[1].PubDate != "" {
+		t.Errorf("Expected no publish date for undated item, got %q", feed.Channel.Item[1].PubDate)
+	}
+}
+
+func TestWriteRSSWithoutSelfURL(t *testing.T) {
+	feed := sampleFeed()
+	feed.SelfURL = ""
+
+	var buf bytes.Buffer
+	if err := feed.Write(&buf, outfeed.FormatRSS); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// RSS 2.0 requires a channel link even when the feed's own URL is unknown
+	var parsed types.RSSFeed
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if parsed.Channel.Link != "https://github.com/abahnj/rssagg" {
+		t.Errorf("Expected the rssagg homepage as channel link, got %q", parsed.Channel.Link)
+	}
+	if strings.Contains(buf.String(), `rel="self"`) {
+		t.Errorf("Expected no self link, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	if err := sampleFeed().Write(&buf, outfeed.FormatAtom); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+		t.Errorf("Expected an Atom feed, got:\n%s", buf.String())
+	}
+	for _, required := range []string{
+		"<id>urn:uuid:6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a</id>",
+		"<updated>2024-01-31T08:15:00Z</updated>",
+		"<name>rssagg</name>",
+	} {
+		if !strings.Contains(buf.String(), required) {
+			t.Errorf("Expected %s in output:\n%s", required, buf.String())
+		}
+	}
+
+	var feed types.AtomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &feed); err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(feed.Entries))
+	}
+
+	entry := feed.Entries[0]
+	if entry.Title.String() != "Generics & you" || types.AlternateLink(entry.Links) != "https://go.dev/blog/generics" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.Summary.Type != "html" || entry.Summary.String() != "<p>Type parameters</p>" {
+		t.Errorf("Expected HTML summary, got %+v", entry.Summary)
+	}
+
+	// Atom requires every entry to have an updated time
+	if feed.Entries[1].Updated != "2024-01-31T08:15:00Z" || feed.Entries[1].Published != "" {
+		t.Errorf("Expected undated entry to use the feed's updated time, got %+v", feed.Entries[1])
+	}
+}
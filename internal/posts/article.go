@@ -0,0 +1,98 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/readability"
+	"github.com/abahnj/rssagg/internal/sanitize"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxArticleSize is the most of a linked page that is read when extracting
+// its article
+const maxArticleSize = 5 << 20
+
+// articleTimeout bounds the time spent fetching one linked page
+const articleTimeout = 30 * time.Second
+
+// maxArticlesPerScrape caps the linked pages fetched for a feed each time
+// it's scraped
+const maxArticlesPerScrape = 10
+
+// FetchArticle downloads the page a post links to, extracts its main
+// article and stores it with the post
+func (s *Service) FetchArticle(ctx context.Context, postID uuid.UUID, pageURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, articleTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error fetching article: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code fetching article: %d", resp.StatusCode)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return fmt.Errorf("linked page is %s, not HTML", mediaType)
+	}
+
+	// Resolve links against the final URL, after any redirects
+	article, err := readability.Extract(io.LimitReader(resp.Body, maxArticleSize), resp.Request.URL)
+	if err != nil {
+		return fmt.Errorf("error extracting article: %w", err)
+	}
+
+	params := database.SetPostArticleParams{
+		ID:      postID,
+		Article: pgtype.Text{String: sanitize.HTML(article.HTML), Valid: true},
+	}
+	if err := s.DB.SetPostArticle(ctx, params); err != nil {
+		return fmt.Errorf("failed to save article: %w", err)
+	}
+	return nil
+}
+
+// PostsMissingArticle lists a feed's posts from the last day whose article
+// hasn't been fetched yet, newest first: new posts, and older ones whose
+// fetch failed on an earlier scrape
+func (s *Service) PostsMissingArticle(ctx context.Context, feedID uuid.UUID) ([]database.GetPostsMissingArticleRow, error) {
+	params := database.GetPostsMissingArticleParams{
+		FeedID: feedID,
+		Limit:  maxArticlesPerScrape,
+	}
+
+	posts, err := s.DB.GetPostsMissingArticle(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts missing an article: %w", err)
+	}
+	return posts, nil
+}
+
+// ArticleText renders a post's body as plain text wrapped at width for the
+// terminal, preferring the fetched article over the feed's content and
+// description
+func ArticleText(post database.Post, width int) string {
+	for _, body := range []pgtype.Text{post.Article, post.Content, post.Description} {
+		if body.Valid && body.String != "" {
+			return sanitize.Text(body.String, width)
+		}
+	}
+	return ""
+}
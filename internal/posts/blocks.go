@@ -0,0 +1,64 @@
+package posts
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/sanitize"
+	"github.com/google/uuid"
+)
+
+// WritePostBlocks writes browse results for reading in a terminal: one block
+// per post with its title, feed, date, read state and links, followed by its
+// summary as plain text wrapped to width, with link footnotes
+func WritePostBlocks(w io.Writer, posts []database.GetPostsForUserRow, enclosures map[uuid.UUID][]database.PostEnclosure, filters Filters, width int) error {
+	var b strings.Builder
+	for i, post := range posts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		// Highlighted posts are marked so they stand out when scanning
+		title := post.Title
+		if filters.Match(FilterHighlight, post.FeedID, post.Title, post.Description.String) {
+			title = "* " + title
+		}
+		b.WriteString(title + "\n")
+
+		published := "no date"
+		if post.PublishedAt.Valid {
+			published = post.PublishedAt.Time.Format(time.DateTime)
+		}
+		state := "unread"
+		if post.IsRead {
+			state = "read"
+		}
+		fmt.Fprintf(&b, "%s | %s | %s\n", post.FeedName, published, state)
+		fmt.Fprintf(&b, "%s\nID: %s\n", post.Url, post.ID)
+
+		if len(post.Authors) > 0 {
+			fmt.Fprintf(&b, "Authors: %s\n", strings.Join(post.Authors, ", "))
+		}
+		if len(post.Categories) > 0 {
+			fmt.Fprintf(&b, "Categories: %s\n", strings.Join(post.Categories, ", "))
+		}
+		for _, enclosure := range enclosures[post.ID] {
+			fmt.Fprintf(&b, "Enclosure: %s\n", enclosure.Url)
+		}
+
+		// The summary is enough to decide whether to read the whole post
+		body := post.Description.String
+		if body == "" {
+			body = post.Content.String
+		}
+		if text := sanitize.Text(body, width); text != "" {
+			fmt.Fprintf(&b, "\n%s\n", text)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
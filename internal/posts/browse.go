@@ -0,0 +1,198 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// cursorTimeFormat is the timestamp layout used in pagination cursors
+const cursorTimeFormat = "2006-01-02T15:04:05.999999"
+
+// Cursor identifies a position in a list of posts for keyset pagination
+type Cursor struct {
+	At time.Time
+	ID uuid.UUID
+}
+
+// String formats the cursor as "<timestamp>,<post-id>"
+func (c Cursor) String() string {
+	return c.At.Format(cursorTimeFormat) + "," + c.ID.String()
+}
+
+// ParseCursor parses a cursor in the "<timestamp>,<post-id>" form
+func ParseCursor(value string) (Cursor, error) {
+	timePart, idPart, found := strings.Cut(value, ",")
+	if !found {
+		return Cursor{}, errors.New("cursor must be in the form <published_at>,<id>")
+	}
+
+	at, err := time.Parse(cursorTimeFormat, strings.TrimSpace(timePart))
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor timestamp: %w", err)
+	}
+
+	id, err := uuid.Parse(strings.TrimSpace(idPart))
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor post ID: %w", err)
+	}
+
+	return Cursor{At: at, ID: id}, nil
+}
+
+// ParseTimeBound parses an absolute date (2006-01-02 or RFC 3339) or a
+// relative age such as 7d, 2w or 36h, which is subtracted from now
+func ParseTimeBound(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	if len(value) > 1 {
+		unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[value[len(value)-1]]
+		if unit > 0 {
+			n, err := strconv.Atoi(value[:len(value)-1])
+			if err == nil && n >= 0 {
+				return now.Add(-time.Duration(n) * unit), nil
+			}
+		}
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid date or age: %s", value)
+	}
+	return now.Add(-d), nil
+}
+
+// BrowseOptions filters and pages the posts returned by BrowsePosts
+type BrowseOptions struct {
+	Limit       int32
+	UnreadOnly  bool
+	Feed        string // feed URL or name
+	Since       time.Time
+	Until       time.Time
+	Cursor      *Cursor // continue after this post in the chosen order
+	Page        int     // 1-based page number, used when Cursor is nil
+	OldestFirst bool
+}
+
+// maxBrowseBatches caps the queries BrowsePosts runs to replace posts
+// hidden by the user's filter rules
+const maxBrowseBatches = 10
+
+// BrowsePosts fetches posts from the user's followed feeds using keyset
+// pagination, newest first unless OldestFirst is set. Posts matching the
+// user's hide rules are left out, and further posts are read to fill
+// their place
+func (s *Service) BrowsePosts(ctx context.Context, userID uuid.UUID, opts BrowseOptions) ([]database.GetPostsForUserRow, error) {
+	filters, err := s.GetFilters(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	hiding := filters.Has(FilterHide)
+
+	params := database.GetPostsForUserParams{
+		UserID:     userID,
+		UnreadOnly: opts.UnreadOnly,
+		Feed:       pgtype.Text{String: opts.Feed, Valid: opts.Feed != ""},
+		Since:      pgtype.Timestamp{Time: opts.Since, Valid: !opts.Since.IsZero()},
+		Until:      pgtype.Timestamp{Time: opts.Until, Valid: !opts.Until.IsZero()},
+		PostLimit:  opts.Limit,
+	}
+
+	// An offset counts hidden posts too, so with hide rules the earlier
+	// pages are read by cursor and skipped instead
+	skip := 0
+	if opts.Cursor != nil {
+		params.CursorAt = pgtype.Timestamp{Time: opts.Cursor.At, Valid: true}
+		params.CursorID = opts.Cursor.ID
+	} else if opts.Page > 1 {
+		if hiding {
+			skip = (opts.Page - 1) * int(opts.Limit)
+		} else {
+			params.PostOffset = int32(opts.Page-1) * opts.Limit
+		}
+	}
+
+	if !hiding {
+		return s.browsePage(ctx, params, opts.OldestFirst)
+	}
+
+	want := skip + int(opts.Limit)
+	params.PostLimit = int32(want)
+	posts := make([]database.GetPostsForUserRow, 0, want)
+	for batch := 1; ; batch++ {
+		page, err := s.browsePage(ctx, params, opts.OldestFirst)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, post := range page {
+			if !filters.Match(FilterHide, post.FeedID, post.Title, post.Description.String) {
+				posts = append(posts, post)
+			}
+		}
+
+		// Stop once there are enough posts, there are no more, or a long
+		// run of hidden posts has used up the batches
+		if len(posts) >= want || int32(len(page)) < params.PostLimit || batch >= maxBrowseBatches {
+			break
+		}
+
+		// Continue after the last post read, hidden or not
+		last := page[len(page)-1]
+		params.CursorAt = last.SortAt
+		params.CursorID = last.ID
+	}
+
+	if len(posts) <= skip {
+		return []database.GetPostsForUserRow{}, nil
+	}
+	posts = posts[skip:]
+	if len(posts) > int(opts.Limit) {
+		posts = posts[:opts.Limit]
+	}
+	return posts, nil
+}
+
+// browsePage runs one query for BrowsePosts in the chosen order
+func (s *Service) browsePage(ctx context.Context, params database.GetPostsForUserParams, oldestFirst bool) ([]database.GetPostsForUserRow, error) {
+	if !oldestFirst {
+		posts, err := s.DB.GetPostsForUser(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get posts for user: %w", err)
+		}
+		return posts, nil
+	}
+
+	rows, err := s.DB.GetPostsForUserOldestFirst(ctx, database.GetPostsForUserOldestFirstParams(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts for user: %w", err)
+	}
+
+	posts := make([]database.GetPostsForUserRow, len(rows))
+	for i, row := range rows {
+		posts[i] = database.GetPostsForUserRow(row)
+	}
+	return posts, nil
+}
+
+// NextCursor returns the cursor that continues after the last post in a page
+func NextCursor(posts []database.GetPostsForUserRow) *Cursor {
+	if len(posts) == 0 {
+		return nil
+	}
+	last := posts[len(posts)-1]
+	return &Cursor{At: last.SortAt.Time, ID: last.ID}
+}
@@ -0,0 +1,42 @@
+package posts
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CanonicalizeURL normalizes a post URL so the same article is recognized
+// across feeds: the scheme and host are lowercased, default ports, fragments
+// and utm_* tracking parameters are removed, the remaining query parameters
+// are sorted and trailing slashes are stripped from the path. URLs that
+// can't be parsed are returned trimmed but otherwise unchanged.
+func CanonicalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	// Encode sorts the parameters by key
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+	return u.String()
+}
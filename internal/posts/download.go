@@ -0,0 +1,152 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+)
+
+// partSuffix is appended to a file while it downloads, so an interrupted
+// download can be told apart from a finished one and resumed
+const partSuffix = ".part"
+
+// DownloadResult describes a finished download
+type DownloadResult struct {
+	Bytes       int64 // size of the downloaded file
+	ResumedFrom int64 // bytes already on disk when the download started
+}
+
+// EpisodeFileName picks the file name for an episode download: the post ID
+// followed by the last element of the enclosure URL's path, or by an
+// extension for the enclosure's type when the URL has no usable name. The
+// ID keeps episodes whose URLs end in the same name, like "media.mp3", from
+// overwriting each other
+func EpisodeFileName(post database.Post, enclosure database.PostEnclosure) string {
+	name := post.ID.String()
+	if u, err := url.Parse(enclosure.Url); err == nil {
+		base := path.Base(u.Path)
+		if base != "." && base != "/" && base != ".." && !strings.ContainsAny(base, `/\`) && path.Ext(base) != "" {
+			return name + "-" + base
+		}
+	}
+
+	if extensions, err := mime.ExtensionsByType(enclosure.Type.String); err == nil && len(extensions) > 0 {
+		name += extensions[0]
+	}
+	return name
+}
+
+// DownloadFile streams a URL to a file. The data is written to the file
+// name plus ".part" and renamed when complete; if that partial file
+// already exists, the download resumes where it stopped with an HTTP Range
+// request, starting over if the server doesn't support ranges
+func DownloadFile(ctx context.Context, client *http.Client, fileURL, filePath string) (DownloadResult, error) {
+	partPath := filePath + partSuffix
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return DownloadResult{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return DownloadResult{}, fmt.Errorf("error downloading %s: %w", fileURL, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, _, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			return DownloadResult{}, fmt.Errorf("server resumed at the wrong position: %q", resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the range, so start over
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file may already hold the whole file; otherwise it
+		// doesn't match what the server has, so start over
+		if _, size, err := parseContentRange(resp.Header.Get("Content-Range")); err == nil && size == offset {
+			if err := os.Rename(partPath, filePath); err != nil {
+				return DownloadResult{}, fmt.Errorf("failed to save download: %w", err)
+			}
+			return DownloadResult{Bytes: offset, ResumedFrom: offset}, nil
+		}
+		if err := os.Remove(partPath); err != nil {
+			return DownloadResult{}, fmt.Errorf("failed to remove partial download: %w", err)
+		}
+		resp.Body.Close()
+		return DownloadFile(ctx, client, fileURL, filePath)
+	default:
+		return DownloadResult{}, fmt.Errorf("unexpected status code downloading %s: %d", fileURL, resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return DownloadResult{}, fmt.Errorf("failed to open download file: %w", err)
+	}
+
+	written, err := io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// Keep what was written so the next attempt can resume
+		return DownloadResult{}, fmt.Errorf("download interrupted after %d bytes: %w", offset+written, err)
+	}
+
+	if err := os.Rename(partPath, filePath); err != nil {
+		return DownloadResult{}, fmt.Errorf("failed to save download: %w", err)
+	}
+	return DownloadResult{Bytes: offset + written, ResumedFrom: offset}, nil
+}
+
+// parseContentRange parses a Content-Range header such as "bytes 100-199/200"
+// or "bytes */200", returning the first byte position and the total size,
+// which is -1 when the server doesn't know it
+func parseContentRange(value string) (start, size int64, err error) {
+	rangePart, sizePart, found := strings.Cut(strings.TrimPrefix(value, "bytes "), "/")
+	if !found || !strings.HasPrefix(value, "bytes ") {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", value)
+	}
+
+	size = -1
+	if sizePart != "*" {
+		if size, err = strconv.ParseInt(sizePart, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range: %q", value)
+		}
+	}
+
+	if rangePart == "*" {
+		return -1, size, nil
+	}
+	startPart, _, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", value)
+	}
+	if start, err = strconv.ParseInt(startPart, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range: %q", value)
+	}
+	return start, size, nil
+}
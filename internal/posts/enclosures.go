@@ -0,0 +1,67 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// createEnclosures stores a new post's enclosures with the post's queries,
+// skipping ones without a URL. Lengths that aren't positive numbers are
+// stored as unknown
+func createEnclosures(ctx context.Context, q *database.Queries, postID uuid.UUID, enclosures []types.RSSEnclosure) error {
+	for _, enclosure := range enclosures {
+		url := strings.TrimSpace(enclosure.URL)
+		if url == "" {
+			continue
+		}
+
+		params := database.CreatePostEnclosureParams{
+			PostID: postID,
+			Url:    url,
+		}
+		if mediaType := strings.TrimSpace(enclosure.Type); mediaType != "" {
+			params.Type = pgtype.Text{String: mediaType, Valid: true}
+		}
+		if length, err := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64); err == nil && length > 0 {
+			params.Length = pgtype.Int8{Int64: length, Valid: true}
+		}
+
+		if err := q.CreatePostEnclosure(ctx, params); err != nil {
+			return fmt.Errorf("failed to save enclosure %s: %w", url, err)
+		}
+	}
+	return nil
+}
+
+// GetEnclosures returns the enclosures of the given posts, keyed by post ID
+func (s *Service) GetEnclosures(ctx context.Context, postIDs []uuid.UUID) (map[uuid.UUID][]database.PostEnclosure, error) {
+	enclosures := make(map[uuid.UUID][]database.PostEnclosure)
+	if len(postIDs) == 0 {
+		return enclosures, nil
+	}
+
+	rows, err := s.DB.GetEnclosuresForPosts(ctx, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enclosures: %w", err)
+	}
+	for _, row := range rows {
+		enclosures[row.PostID] = append(enclosures[row.PostID], row)
+	}
+	return enclosures, nil
+}
+
+// PostIDs returns the IDs of a page of posts
+func PostIDs(posts []database.GetPostsForUserRow) []uuid.UUID {
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return ids
+}
@@ -0,0 +1,271 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/sanitize"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Filter rule actions
+const (
+	FilterHide      = "hide"      // leave matching posts out of browsing
+	FilterHighlight = "highlight" // mark matching posts when browsing
+	FilterStar      = "star"      // star matching posts as they're scraped
+)
+
+// ErrFilterNotFound is returned when removing a filter rule the user doesn't have
+var ErrFilterNotFound = errors.New("filter rule not found")
+
+// FilterOptions describes a new filter rule
+type FilterOptions struct {
+	FeedURL    string // empty to match posts in every feed
+	TitleRegex string
+	Keyword    string
+	Action     string
+}
+
+// ParseFilterArgs parses the arguments of "filter add": --feed <url>,
+// --title-regex <regex>, --keyword <word> and --action hide|highlight|star.
+// A rule needs a regex, a keyword or both, and an action
+func ParseFilterArgs(args []string) (FilterOptions, error) {
+	var opts FilterOptions
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i+1 >= len(args) {
+			return FilterOptions{}, fmt.Errorf("%s requires a value", arg)
+		}
+		i++
+		value := args[i]
+
+		switch arg {
+		case "--feed":
+			opts.FeedURL = value
+		case "--title-regex":
+			opts.TitleRegex = value
+		case "--keyword":
+			opts.Keyword = value
+		case "--action":
+			opts.Action = value
+		default:
+			return FilterOptions{}, fmt.Errorf("unknown flag: %s", arg)
+		}
+	}
+
+	if opts.TitleRegex == "" && strings.TrimSpace(opts.Keyword) == "" {
+		return FilterOptions{}, errors.New("a filter needs --title-regex, --keyword or both")
+	}
+	switch opts.Action {
+	case FilterHide, FilterHighlight, FilterStar:
+	case "":
+		return FilterOptions{}, errors.New("--action is required (hide, highlight or star)")
+	default:
+		return FilterOptions{}, fmt.Errorf("invalid action: %s (use hide, highlight or star)", opts.Action)
+	}
+	return opts, nil
+}
+
+// Filter is a filter rule ready for matching
+type Filter struct {
+	FeedID     pgtype.UUID // matches every feed when not valid
+	TitleRegex *regexp.Regexp
+	Keyword    string // lowercased
+	Action     string
+}
+
+// CompileFilter prepares a stored filter rule for matching
+func CompileFilter(rule database.FilterRule) (Filter, error) {
+	return compileFilter(rule.FeedID, rule.TitleRegex, rule.Keyword, rule.Action)
+}
+
+// compileFilter prepares a filter rule's columns for matching
+func compileFilter(feedID pgtype.UUID, titleRegex, keyword pgtype.Text, action string) (Filter, error) {
+	filter := Filter{
+		FeedID:  feedID,
+		Keyword: strings.ToLower(strings.TrimSpace(keyword.String)),
+		Action:  action,
+	}
+	if titleRegex.Valid {
+		re, err := regexp.Compile(titleRegex.String)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid title regex: %w", err)
+		}
+		filter.TitleRegex = re
+	}
+	return filter, nil
+}
+
+// Matches reports whether a post matches the rule: it must be in the
+// rule's feed, if it has one, its title must match the regex and its title
+// or plain-text description must contain the keyword, ignoring case
+func (f Filter) Matches(feedID uuid.UUID, title, text string) bool {
+	if f.FeedID.Valid && uuid.UUID(f.FeedID.Bytes) != feedID {
+		return false
+	}
+	if f.TitleRegex != nil && !f.TitleRegex.MatchString(title) {
+		return false
+	}
+	if f.Keyword != "" &&
+		!strings.Contains(strings.ToLower(title), f.Keyword) &&
+		!strings.Contains(strings.ToLower(text), f.Keyword) {
+		return false
+	}
+	return true
+}
+
+// Filters are one user's filter rules
+type Filters []Filter
+
+// Has reports whether any rule has the given action
+func (fs Filters) Has(action string) bool {
+	for _, f := range fs {
+		if f.Action == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether any rule with the given action matches a post.
+// The description is HTML and is only rendered as text when a rule needs it
+func (fs Filters) Match(action string, feedID uuid.UUID, title, description string) bool {
+	text, rendered := "", false
+	for _, f := range fs {
+		if f.Action != action {
+			continue
+		}
+		if f.Keyword != "" && !rendered {
+			text, rendered = sanitize.Inline(description), true
+		}
+		if f.Matches(feedID, title, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateFilterRule validates and stores a filter rule for the user
+func (s *Service) CreateFilterRule(ctx context.Context, userID uuid.UUID, opts FilterOptions) (database.FilterRule, error) {
+	if opts.TitleRegex != "" {
+		if _, err := regexp.Compile(opts.TitleRegex); err != nil {
+			return database.FilterRule{}, fmt.Errorf("invalid title regex: %w", err)
+		}
+	}
+
+	var feedID pgtype.UUID
+	if opts.FeedURL != "" {
+		feed, err := s.DB.GetFeedByURL(ctx, opts.FeedURL)
+		if err != nil {
+			return database.FilterRule{}, fmt.Errorf("feed with URL %s not found: %w", opts.FeedURL, err)
+		}
+		feedID = pgtype.UUID{Bytes: feed.ID, Valid: true}
+	}
+
+	keyword := strings.TrimSpace(opts.Keyword)
+	params := database.CreateFilterRuleParams{
+		ID:         uuid.New(),
+		UserID:     userID,
+		FeedID:     feedID,
+		TitleRegex: pgtype.Text{String: opts.TitleRegex, Valid: opts.TitleRegex != ""},
+		Keyword:    pgtype.Text{String: keyword, Valid: keyword != ""},
+		Action:     opts.Action,
+	}
+
+	rule, err := s.DB.CreateFilterRule(ctx, params)
+	if err != nil {
+		return database.FilterRule{}, fmt.Errorf("failed to create filter rule: %w", err)
+	}
+	return rule, nil
+}
+
+// GetFilterRules fetches the user's filter rules, oldest first
+func (s *Service) GetFilterRules(ctx context.Context, userID uuid.UUID) ([]database.GetFilterRulesForUserRow, error) {
+	rules, err := s.DB.GetFilterRulesForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteFilterRule removes one of the user's filter rules
+func (s *Service) DeleteFilterRule(ctx context.Context, userID, ruleID uuid.UUID) error {
+	params := database.DeleteFilterRuleParams{
+		ID:     ruleID,
+		UserID: userID,
+	}
+
+	count, err := s.DB.DeleteFilterRule(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to delete filter rule: %w", err)
+	}
+	if count == 0 {
+		return ErrFilterNotFound
+	}
+	return nil
+}
+
+// GetFilters fetches the user's filter rules ready for matching
+func (s *Service) GetFilters(ctx context.Context, userID uuid.UUID) (Filters, error) {
+	rows, err := s.GetFilterRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rules are validated when they're created, so one that no longer
+	// compiles is skipped rather than failing every listing
+	filters := make(Filters, 0, len(rows))
+	for _, row := range rows {
+		if filter, err := compileFilter(row.FeedID, row.TitleRegex, row.Keyword, row.Action); err == nil {
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
+
+// FeedFilters are the filter rules that apply to a feed, by the ID of the
+// following user they belong to
+type FeedFilters map[uuid.UUID]Filters
+
+// GetFeedFilters fetches the filter rules of every user following a feed
+// that apply to it
+func (s *Service) GetFeedFilters(ctx context.Context, feedID uuid.UUID) (FeedFilters, error) {
+	rules, err := s.DB.GetFilterRulesForFeed(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter rules for feed: %w", err)
+	}
+
+	filters := make(FeedFilters)
+	for _, rule := range rules {
+		if filter, err := CompileFilter(rule); err == nil {
+			filters[rule.UserID] = append(filters[rule.UserID], filter)
+		}
+	}
+	return filters, nil
+}
+
+// StarMatching stars a newly scraped post for every user with a star rule
+// that matches it, returning how many users it was starred for
+func (s *Service) StarMatching(ctx context.Context, filters FeedFilters, feedID, postID uuid.UUID, title, description string) (int, error) {
+	starred := 0
+	for userID, userFilters := range filters {
+		if !userFilters.Match(FilterStar, feedID, title, description) {
+			continue
+		}
+
+		params := database.StarPostParams{
+			UserID: userID,
+			ID:     postID,
+		}
+		if err := s.DB.StarPost(ctx, params); err != nil {
+			return starred, fmt.Errorf("failed to star post: %w", err)
+		}
+		starred++
+	}
+	return starred, nil
+}
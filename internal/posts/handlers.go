@@ -2,66 +2,540 @@ package posts
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/abahnj/rssagg/internal/cli"
 	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/outfeed"
+	"github.com/abahnj/rssagg/internal/sanitize"
+	"github.com/google/uuid"
 )
 
+// searchResultLimit is the number of results the search command shows
+const searchResultLimit = 20
+
+// defaultOutfeedLimit is the number of posts outfeed includes by default
+const defaultOutfeedLimit = 20
+
+// ParseBrowseArgs parses the browse command's optional limit and flags:
+// --unread, --feed <url|name>, --since <date|age>, --until <date|age>,
+// --sort newest|oldest, --page <n>, --before <cursor> and --after <cursor>
+func ParseBrowseArgs(args []string, now time.Time) (BrowseOptions, error) {
+	// Default limit is 10 posts
+	opts := BrowseOptions{Limit: 10}
+	var before, after string
+	sortOrder := ""
+	
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--unread" {
+			opts.UnreadOnly = true
+			continue
+		}
+		
+		if !strings.HasPrefix(arg, "--") {
+			parsedLimit, err := strconv.Atoi(arg)
+			if err != nil || parsedLimit < 1 {
+				return BrowseOptions{}, fmt.Errorf("invalid limit value: %s", arg)
+			}
+			opts.Limit = int32(parsedLimit)
+			continue
+		}
+		
+		// Every other flag takes a value
+		if i+1 >= len(args) {
+			return BrowseOptions{}, fmt.Errorf("%s requires a value", arg)
+		}
+		i++
+		value := args[i]
+		
+		var err error
+		switch arg {
+		case "--feed":
+			opts.Feed = value
+		case "--since":
+			opts.Since, err = ParseTimeBound(value, now)
+		case "--until":
+			opts.Until, err = ParseTimeBound(value, now)
+		case "--sort":
+			if value != "newest" && value != "oldest" {
+				return BrowseOptions{}, fmt.Errorf("invalid sort order: %s (use newest or oldest)", value)
+			}
+			sortOrder = value
+		case "--page":
+			opts.Page, err = strconv.Atoi(value)
+			if err == nil && opts.Page < 1 {
+				err = errors.New("page must be at least 1")
+			}
+		case "--before":
+			before = value
+		case "--after":
+			after = value
+		default:
+			return BrowseOptions{}, fmt.Errorf("unknown flag: %s", arg)
+		}
+		if err != nil {
+			return BrowseOptions{}, fmt.Errorf("invalid %s value: %w", arg, err)
+		}
+	}
+	
+	// --before pages through newest-first results and --after through oldest-first ones
+	if before != "" && after != "" {
+		return BrowseOptions{}, errors.New("use either --before or --after, not both")
+	}
+	if before != "" && sortOrder == "oldest" {
+		return BrowseOptions{}, errors.New("--before can't be used with --sort oldest, use --after")
+	}
+	if after != "" && sortOrder == "newest" {
+		return BrowseOptions{}, errors.New("--after can't be used with --sort newest, use --before")
+	}
+	opts.OldestFirst = sortOrder == "oldest" || after != ""
+	
+	if cursor := before + after; cursor != "" {
+		if opts.Page > 0 {
+			return BrowseOptions{}, errors.New("use either --page or a cursor, not both")
+		}
+		parsed, err := ParseCursor(cursor)
+		if err != nil {
+			return BrowseOptions{}, err
+		}
+		opts.Cursor = &parsed
+	}
+	
+	return opts, nil
+}
+
 // HandlerBrowse handles the browse command to view posts from followed feeds
 func HandlerBrowse(s *cli.State, cmd cli.Command, user database.User) error {
 	ctx := context.Background()
 	service := NewService(*s.Db)
 	
+	// Parse the limit argument and filter flags
+	opts, err := ParseBrowseArgs(cmd.Args, time.Now())
+	if err != nil {
+		return err
+	}
+	
+	// Get posts for the user
+	posts, err := service.BrowsePosts(ctx, user.ID, opts)
+	if err != nil {
+		return err
+	}
+	
+	enclosures, err := service.GetEnclosures(ctx, PostIDs(posts))
+	if err != nil {
+		return err
+	}
+	
+	filters, err := service.GetFilters(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	
+	switch s.Output {
+	case cli.FormatJSON, cli.FormatCSV, cli.FormatTSV:
+		// Machine-readable output gets every column
+		table := cli.NewTable("id", "title", "feed", "url", "published_at", "read", "highlighted", "authors", "categories", "enclosures", "description", "content")
+		for _, post := range posts {
+			enclosureURLs := make([]string, 0, len(enclosures[post.ID]))
+			for _, enclosure := range enclosures[post.ID] {
+				enclosureURLs = append(enclosureURLs, enclosure.Url)
+			}
+			highlighted := filters.Match(FilterHighlight, post.FeedID, post.Title, post.Description.String)
+			table.AddRow(post.ID, post.Title, post.FeedName, post.Url, cli.NullableTime(post.PublishedAt), post.IsRead, highlighted,
+				post.Authors, post.Categories, enclosureURLs, sanitize.Inline(post.Description.String), sanitize.Inline(post.Content.String))
+		}
+		if err := s.Render(table); err != nil {
+			return err
+		}
+	default:
+		// Too many columns for a table, so show a block per post instead
+		if len(posts) == 0 {
+			fmt.Fprintln(s.Writer(), "No posts found in your followed feeds")
+		} else if err := WritePostBlocks(s.Writer(), posts, enclosures, filters, cli.TextWidth()); err != nil {
+			return err
+		}
+	}
+	
+	// Show how to continue when there may be more posts
+	if int32(len(posts)) == opts.Limit {
+		flag := "--before"
+		if opts.OldestFirst {
+			flag = "--after"
+		}
+		// Hints go to stderr so they don't mix with machine-readable output
+		fmt.Fprintf(os.Stderr, "More posts: browse %s %s\n", flag, NextCursor(posts))
+	}
+	
+	return nil
+}
+
+// HandlerRead handles the read command to show a post as plain text and
+// mark it as read
+func HandlerRead(s *cli.State, cmd cli.Command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+	
+	service := NewService(*s.Db)
+	post, err := service.MarkRead(context.Background(), user.ID, postID)
+	if err != nil {
+		return err
+	}
+	
+	fmt.Println(post.Title)
+	fmt.Println(post.Url)
+	if text := ArticleText(post, cli.TextWidth()); text != "" {
+		fmt.Printf("\n%s\n", text)
+	}
+	return nil
+}
+
+// HandlerUnread handles the unread command to mark a post as unread
+func HandlerUnread(s *cli.State, cmd cli.Command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+	
+	service := NewService(*s.Db)
+	post, err := service.MarkUnread(context.Background(), user.ID, postID)
+	if err != nil {
+		return err
+	}
+	
+	fmt.Printf("Marked as unread: %s\n", post.Title)
+	return nil
+}
+
+// HandlerMarkAll handles the markall command to mark every post in the
+// user's followed feeds, or in a single feed, as read
+func HandlerMarkAll(s *cli.State, cmd cli.Command, user database.User) error {
+	feedURL := ""
+	if len(cmd.Args) > 0 {
+		feedURL = cmd.Args[0]
+	}
+	
+	service := NewService(*s.Db)
+	count, err := service.MarkAllRead(context.Background(), user.ID, feedURL)
+	if err != nil {
+		return err
+	}
+	
+	fmt.Printf("Marked %d posts as read\n", count)
+	return nil
+}
+
+// HandlerStar handles the star command to save a post
+func HandlerStar(s *cli.State, cmd cli.Command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+	
+	service := NewService(*s.Db)
+	post, err := service.StarPost(context.Background(), user.ID, postID)
+	if err != nil {
+		return err
+	}
+	
+	fmt.Printf("Starred: %s\n", post.Title)
+	return nil
+}
+
+// HandlerUnstar handles the unstar command to remove a saved post
+func HandlerUnstar(s *cli.State, cmd cli.Command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+	
+	service := NewService(*s.Db)
+	if err := service.UnstarPost(context.Background(), user.ID, postID); err != nil {
+		return err
+	}
+	
+	fmt.Printf("Unstarred post %s\n", postID)
+	return nil
+}
+
+// HandlerStarred handles the starred command to list saved posts
+func HandlerStarred(s *cli.State, cmd cli.Command, user database.User) error {
+	ctx := context.Background()
+	service := NewService(*s.Db)
+	
 	// Default limit is 10 posts
 	limit := int32(10)
 	
 	// Parse limit argument if provided
 	if len(cmd.Args) > 0 {
 		parsedLimit, err := strconv.Atoi(cmd.Args[0])
-		if err != nil {
-			return fmt.Errorf("invalid limit value: %w", err)
+		if err != nil || parsedLimit < 1 {
+			return fmt.Errorf("invalid limit value: %s", cmd.Args[0])
 		}
 		limit = int32(parsedLimit)
 	}
 	
-	// Get posts for the user
-	posts, err := service.GetPostsForUser(ctx, user.ID, limit)
+	starred, err := service.GetStarredPosts(ctx, user.ID, limit)
 	if err != nil {
 		return err
 	}
 	
-	if len(posts) == 0 {
-		fmt.Println("No posts found in your followed feeds")
-		return nil
+	table := cli.NewTable("id", "title", "feed", "url", "published_at", "starred_at")
+	table.Empty = "No starred posts"
+	for _, post := range starred {
+		table.AddRow(post.PostID, post.Title, post.FeedName, post.Url, cli.NullableTime(post.PublishedAt), post.StarredAt.Time)
 	}
 	
-	fmt.Printf("Found %d posts from your followed feeds:\n\n", len(posts))
+	return s.Render(table)
+}
+
+// HandlerFilter handles the filter command to manage filter rules:
+// "filter add" creates one, "filter list" shows them and "filter rm <id>"
+// removes one
+func HandlerFilter(s *cli.State, cmd cli.Command, user database.User) error {
+	ctx := context.Background()
+	service := NewService(*s.Db)
 	
-	// Display each post
-	for i, post := range posts {
-		fmt.Printf("%d. %s\n", i+1, post.Title)
-		fmt.Printf("   Feed: %s\n", post.FeedName)
-		fmt.Printf("   URL: %s\n", post.Url)
+	usage := errors.New("usage: filter add [--feed <url>] [--title-regex <regex>] [--keyword <word>] --action hide|highlight|star | filter list | filter rm <rule-id>")
+	if len(cmd.Args) < 1 {
+		return usage
+	}
+	
+	switch cmd.Args[0] {
+	case "add":
+		opts, err := ParseFilterArgs(cmd.Args[1:])
+		if err != nil {
+			return err
+		}
 		
-		// Show published date if available
-		if post.PublishedAt.Valid {
-			fmt.Printf("   Published: %s\n", post.PublishedAt.Time.Format("2006-01-02 15:04:05"))
+		rule, err := service.CreateFilterRule(ctx, user.ID, opts)
+		if err != nil {
+			return err
 		}
 		
-		// Show description if available
-		if post.Description.Valid {
-			// Truncate description if it's too long
-			description := post.Description.String
-			if len(description) > 100 {
-				description = description[:100] + "..."
+		fmt.Printf("Added filter rule %s\n", rule.ID)
+		return nil
+		
+	case "list":
+		rules, err := service.GetFilterRules(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		
+		table := cli.NewTable("id", "action", "feed", "title_regex", "keyword", "created_at")
+		table.Empty = "No filter rules"
+		for _, rule := range rules {
+			feed := "all feeds"
+			if rule.FeedUrl.Valid {
+				feed = rule.FeedUrl.String
 			}
-			fmt.Printf("   Description: %s\n", description)
+			table.AddRow(rule.ID, rule.Action, feed, rule.TitleRegex.String, rule.Keyword.String, rule.CreatedAt.Time)
+		}
+		return s.Render(table)
+		
+	case "rm":
+		if len(cmd.Args) < 2 {
+			return errors.New("filter rule ID is required")
+		}
+		ruleID, err := uuid.Parse(cmd.Args[1])
+		if err != nil {
+			return fmt.Errorf("invalid filter rule ID: %w", err)
 		}
 		
-		fmt.Println()
+		if err := service.DeleteFilterRule(ctx, user.ID, ruleID); err != nil {
+			return err
+		}
+		
+		fmt.Printf("Removed filter rule %s\n", ruleID)
+		return nil
+		
+	default:
+		return usage
+	}
+}
+
+// HandlerSearch handles the search command to find posts by keyword
+func HandlerSearch(s *cli.State, cmd cli.Command, user database.User) error {
+	ctx := context.Background()
+	service := NewService(*s.Db)
+	
+	// Search followed feeds unless --all is given
+	allFeeds := false
+	var terms []string
+	for _, arg := range cmd.Args {
+		if arg == "--all" {
+			allFeeds = true
+			continue
+		}
+		terms = append(terms, arg)
+	}
+	
+	if len(terms) == 0 {
+		return errors.New("search query is required")
+	}
+	
+	results, err := service.SearchPosts(ctx, user.ID, strings.Join(terms, " "), allFeeds, searchResultLimit)
+	if err != nil {
+		return err
+	}
+	
+	// Snippets are cut from HTML descriptions, so strip the tags from them
+	table := cli.NewTable("id", "title", "feed", "url", "published_at", "rank", "match")
+	table.Empty = "No posts match your search"
+	for _, result := range results {
+		table.AddRow(result.ID, result.Title, result.FeedName, result.Url, cli.NullableTime(result.PublishedAt), result.Rank, sanitize.Inline(result.Snippet))
 	}
 	
+	return s.Render(table)
+}
+
+// HandlerOutfeed handles the outfeed command to write the user's posts as a
+// single RSS or Atom feed: outfeed [--format rss|atom] [--limit N]
+func HandlerOutfeed(s *cli.State, cmd cli.Command, user database.User) error {
+	ctx := context.Background()
+	service := NewService(*s.Db)
+	
+	format := outfeed.FormatRSS
+	limit := int32(defaultOutfeedLimit)
+	for i := 0; i < len(cmd.Args); i++ {
+		arg := cmd.Args[i]
+		if arg != "--format" && arg != "--limit" {
+			return fmt.Errorf("unknown argument: %s", arg)
+		}
+		if i+1 >= len(cmd.Args) {
+			return fmt.Errorf("%s requires a value", arg)
+		}
+		i++
+		value := cmd.Args[i]
+		
+		if arg == "--format" {
+			parsed, err := outfeed.ParseFormat(value)
+			if err != nil {
+				return err
+			}
+			format = parsed
+			continue
+		}
+		
+		parsedLimit, err := strconv.Atoi(value)
+		if err != nil || parsedLimit < 1 {
+			return fmt.Errorf("invalid limit value: %s", value)
+		}
+		limit = int32(parsedLimit)
+	}
+	
+	posts, err := service.GetPostsForUser(ctx, user.ID, limit)
+	if err != nil {
+		return err
+	}
+	
+	return NewOutputFeed(user, posts, time.Now()).Write(os.Stdout, format)
+}
+
+// HandlerPodcasts handles the podcasts command to list the newest episodes
+// from followed feeds: posts with an audio or video enclosure
+func HandlerPodcasts(s *cli.State, cmd cli.Command, user database.User) error {
+	ctx := context.Background()
+	service := NewService(*s.Db)
+	
+	// Default limit is 10 episodes
+	limit := int32(10)
+	
+	// Parse limit argument if provided
+	if len(cmd.Args) > 0 {
+		parsedLimit, err := strconv.Atoi(cmd.Args[0])
+		if err != nil || parsedLimit < 1 {
+			return fmt.Errorf("invalid limit value: %s", cmd.Args[0])
+		}
+		limit = int32(parsedLimit)
+	}
+	
+	episodes, err := service.GetPodcastEpisodes(ctx, user.ID, limit)
+	if err != nil {
+		return err
+	}
+	
+	table := cli.NewTable("id", "title", "feed", "episode", "duration", "published_at", "length", "enclosure_url")
+	table.Empty = "No podcast episodes found in your followed feeds"
+	for _, episode := range episodes {
+		var number, length any
+		if episode.Episode.Valid {
+			number = episode.Episode.Int32
+		}
+		if episode.EnclosureLength.Valid {
+			length = episode.EnclosureLength.Int64
+		}
+		table.AddRow(episode.ID, episode.Title, episode.FeedName, number, FormatEpisodeDuration(episode.DurationSeconds),
+			cli.NullableTime(episode.PublishedAt), length, episode.EnclosureUrl)
+	}
+	
+	return s.Render(table)
+}
+
+// HandlerDownload handles the download command to save a post's episode to
+// a directory (default: the current one), resuming an interrupted download
+func HandlerDownload(s *cli.State, cmd cli.Command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+	
+	dir := "."
+	if len(cmd.Args) > 1 {
+		dir = cmd.Args[1]
+	}
+	
+	// Stop cleanly on Ctrl-C, keeping the partial file to resume later
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	
+	service := NewService(*s.Db)
+	post, enclosure, err := service.GetEpisode(ctx, postID)
+	if err != nil {
+		return err
+	}
+	
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+	filePath := filepath.Join(dir, EpisodeFileName(post, enclosure))
+	if _, err := os.Stat(filePath); err == nil {
+		fmt.Printf("Already downloaded: %s\n", filePath)
+		return nil
+	}
+	
+	fmt.Printf("Downloading \"%s\" to %s\n", post.Title, filePath)
+	result, err := DownloadFile(ctx, &http.Client{}, enclosure.Url, filePath)
+	if err != nil {
+		return err
+	}
+	
+	if result.ResumedFrom > 0 {
+		fmt.Printf("Resumed after %d bytes\n", result.ResumedFrom)
+	}
+	fmt.Printf("Saved %d bytes to %s\n", result.Bytes, filePath)
 	return nil
-}
\ No newline at end of file
+}
+
+// parsePostID reads the post ID from a command's first argument
+func parsePostID(cmd cli.Command) (uuid.UUID, error) {
+	if len(cmd.Args) < 1 {
+		return uuid.UUID{}, errors.New("post ID is required")
+	}
+	
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid post ID: %w", err)
+	}
+	return postID, nil
+}
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/sanitize"
 	"github.com/abahnj/rssagg/internal/types"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -28,7 +30,9 @@ func NewService(db database.Queries) *Service {
 // CreatePostResult contains the result of creating a post
 type CreatePostResult struct {
 	Created bool
-	Err     error
+	// PostID is the new post's ID when it was created
+	PostID uuid.UUID
+	Err    error
 }
 
 // CreatePost adds a new post to the database and returns whether it was created or already existed
@@ -51,28 +55,59 @@ func (s *Service) CreatePost(ctx context.Context, feed database.Feed, item types
 		}
 	}
 
-	// Prepare description
+	// Prepare description, keeping only safe HTML
 	var description pgtype.Text
 	if item.Description != "" {
-		description.String = item.Description
+		description.String = sanitize.HTML(item.Description)
 		description.Valid = true
 	}
 
-	// Create post parameters
+	// Identify the post by its GUID when the feed provides one
+	var guid pgtype.Text
+	if trimmed := strings.TrimSpace(item.GUID); trimmed != "" {
+		guid.String = trimmed
+		guid.Valid = true
+	}
+
+	// Keep the full article when the feed provides more than a summary
+	var content pgtype.Text
+	if item.Content != "" && item.Content != item.Description {
+		content.String = sanitize.HTML(item.Content)
+		content.Valid = true
+	}
+
+	duration, episode, image := episodeParams(item)
+
+	// Create post parameters; the array columns are NOT NULL, so nil slices
+	// must become empty ones
 	params := database.CreatePostParams{
-		ID:          uuid.New(),
-		Title:       item.Title,
-		Url:         item.Link,
-		Description: description,
-		PublishedAt: publishedAt,
-		FeedID:      feed.ID,
+		ID:              uuid.New(),
+		Title:           item.Title,
+		Url:             item.Link,
+		Description:     description,
+		PublishedAt:     publishedAt,
+		FeedID:          feed.ID,
+		Guid:            guid,
+		CanonicalUrl:    CanonicalizeURL(item.Link),
+		Content:         content,
+		Authors:         append([]string{}, item.Authors...),
+		Categories:      append([]string{}, item.Categories...),
+		DurationSeconds: duration,
+		Episode:         episode,
+		ImageUrl:        image,
 	}
 
-	// Insert post into database
-	_, err := s.DB.CreatePost(ctx, params)
+	// Insert the post and its enclosures together, so a failed enclosure
+	// doesn't leave the post saved without it
+	err := s.DB.InTx(ctx, func(q *database.Queries) error {
+		if _, err := q.CreatePost(ctx, params); err != nil {
+			return fmt.Errorf("failed to create post: %w", err)
+		}
+		return createEnclosures(ctx, q, params.ID, item.Enclosures)
+	})
 	if err != nil {
-		// If the error is a duplicate key error, return false but no error
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		// No row is returned when the feed already has this GUID or URL
+		if errors.Is(err, pgx.ErrNoRows) {
 			return CreatePostResult{
 				Created: false,
 				Err:     nil,
@@ -80,40 +115,36 @@ func (s *Service) CreatePost(ctx context.Context, feed database.Feed, item types
 		}
 		return CreatePostResult{
 			Created: false,
-			Err:     fmt.Errorf("failed to create post: %w", err),
+			Err:     err,
 		}
 	}
 
 	return CreatePostResult{
 		Created: true,
+		PostID:  params.ID,
 		Err:     nil,
 	}
 }
 
 // GetPostsForUser fetches posts for a specific user with a limit
 func (s *Service) GetPostsForUser(ctx context.Context, userID uuid.UUID, limit int32) ([]database.GetPostsForUserRow, error) {
-	params := database.GetPostsForUserParams{
-		UserID: userID,
-		Limit:  limit,
-	}
-
-	posts, err := s.DB.GetPostsForUser(ctx, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get posts for user: %w", err)
-	}
+	return s.BrowsePosts(ctx, userID, BrowseOptions{Limit: limit})
+}
 
-	return posts, nil
+// GetUnreadPostsForUser fetches posts the user hasn't read yet with a limit
+func (s *Service) GetUnreadPostsForUser(ctx context.Context, userID uuid.UUID, limit int32) ([]database.GetPostsForUserRow, error) {
+	return s.BrowsePosts(ctx, userID, BrowseOptions{Limit: limit, UnreadOnly: true})
 }
 
 // parseRSSTime attempts to parse a time string from an RSS feed in various formats
 func parseRSSTime(timeStr string) (time.Time, error) {
 	// Common time formats found in RSS feeds
 	formats := []string{
-		time.RFC1123Z,     // "Mon, 02 Jan 2006 15:04:05 -0700"
-		time.RFC1123,      // "Mon, 02 Jan 2006 15:04:05 MST"
-		time.RFC3339,      // "2006-01-02T15:04:05Z07:00"
-		time.RFC822Z,      // "02 Jan 06 15:04 -0700"
-		time.RFC822,       // "02 Jan 06 15:04 MST"
+		time.RFC1123Z, // "Mon, 02 Jan 2006 15:04:05 -0700"
+		time.RFC1123,  // "Mon, 02 Jan 2006 15:04:05 MST"
+		time.RFC3339,  // "2006-01-02T15:04:05Z07:00"
+		time.RFC822Z,  // "02 Jan 06 15:04 -0700"
+		time.RFC822,   // "02 Jan 06 15:04 MST"
 		"2006-01-02T15:04:05-07:00",
 		"2006-01-02 15:04:05",
 		"2006-01-02",
@@ -121,6 +152,8 @@ func parseRSSTime(timeStr string) (time.Time, error) {
 		"02 Jan 2006 15:04:05 -0700",
 		"Mon, 2 Jan 2006 15:04:05 MST",
 		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"2006-01-02T15:04Z07:00", // W3CDTF without seconds, used by dc:date
+		"2006-01",
 	}
 
 	var lastErr error
@@ -134,4 +167,4 @@ func parseRSSTime(timeStr string) (time.Time, error) {
 
 	// If we got here, none of the formats matched
 	return time.Time{}, fmt.Errorf("could not parse time string: %s, last error: %w", timeStr, lastErr)
-}
\ No newline at end of file
+}
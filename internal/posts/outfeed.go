@@ -0,0 +1,43 @@
+package posts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/outfeed"
+)
+
+// NewOutputFeed builds the combined feed of a user's posts, as returned by
+// GetPostsForUser. The feed is as fresh as its newest post, or now if it has none
+func NewOutputFeed(user database.User, posts []database.GetPostsForUserRow, now time.Time) *outfeed.Feed {
+	feed := &outfeed.Feed{
+		ID:          "urn:uuid:" + user.ID.String(),
+		Title:       fmt.Sprintf("%s's feeds", user.Name),
+		Description: fmt.Sprintf("Posts from the feeds %s follows, collected by rssagg", user.Name),
+		Updated:     now,
+	}
+
+	var newest time.Time
+	for _, post := range posts {
+		item := outfeed.Item{
+			ID:          "urn:uuid:" + post.ID.String(),
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description.String,
+			Source:      post.FeedName,
+		}
+		if post.PublishedAt.Valid {
+			item.Published = post.PublishedAt.Time
+		}
+		if post.SortAt.Time.After(newest) {
+			newest = post.SortAt.Time
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	if !newest.IsZero() {
+		feed.Updated = newest
+	}
+	return feed
+}
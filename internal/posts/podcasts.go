@@ -0,0 +1,102 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrNoEpisode is returned when a post has no audio or video enclosure to download
+var ErrNoEpisode = errors.New("post has no audio or video enclosure")
+
+// ParseEpisodeDuration parses an itunes:duration value, which is either a
+// number of seconds or a clock time like 1:02:03 or 45:10
+func ParseEpisodeDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	parts := strings.Split(value, ":")
+	if value == "" || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid episode duration: %q", value)
+	}
+
+	var seconds float64
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		// Only the seconds may be fractional, and minutes and seconds of a
+		// clock time stay below 60
+		if err != nil || n < 0 || (i < len(parts)-1 && strings.Contains(part, ".")) || (i > 0 && n >= 60) {
+			return 0, fmt.Errorf("invalid episode duration: %q", value)
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// FormatEpisodeDuration formats a duration in seconds as a clock time like
+// 1:02:03, or returns nil when it's unknown
+func FormatEpisodeDuration(seconds pgtype.Int4) any {
+	if !seconds.Valid {
+		return nil
+	}
+	h, m, s := seconds.Int32/3600, seconds.Int32/60%60, seconds.Int32%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// episodeParams converts an item's iTunes episode details to post columns,
+// leaving values that can't be parsed unset
+func episodeParams(item types.RSSItem) (duration, episode pgtype.Int4, image pgtype.Text) {
+	if item.ITunesDuration != "" {
+		if d, err := ParseEpisodeDuration(item.ITunesDuration); err == nil && d.Seconds() <= float64(1<<31-1) {
+			duration = pgtype.Int4{Int32: int32(d.Seconds()), Valid: true}
+		}
+	}
+	if n, err := strconv.ParseInt(strings.TrimSpace(item.ITunesEpisode), 10, 32); err == nil && n > 0 {
+		episode = pgtype.Int4{Int32: int32(n), Valid: true}
+	}
+	if href := strings.TrimSpace(item.ITunesImage.Href); href != "" {
+		image = pgtype.Text{String: href, Valid: true}
+	}
+	return duration, episode, image
+}
+
+// GetPodcastEpisodes fetches the newest posts with an audio or video
+// enclosure from the user's followed feeds
+func (s *Service) GetPodcastEpisodes(ctx context.Context, userID uuid.UUID, limit int32) ([]database.GetPodcastEpisodesForUserRow, error) {
+	episodes, err := s.DB.GetPodcastEpisodesForUser(ctx, database.GetPodcastEpisodesForUserParams{
+		UserID: userID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get podcast episodes: %w", err)
+	}
+	return episodes, nil
+}
+
+// GetEpisode fetches a post along with its audio or video enclosure,
+// returning ErrNoEpisode if it has none
+func (s *Service) GetEpisode(ctx context.Context, postID uuid.UUID) (database.Post, database.PostEnclosure, error) {
+	post, err := s.DB.GetPost(ctx, postID)
+	if err != nil {
+		return database.Post{}, database.PostEnclosure{}, fmt.Errorf("post %s not found: %w", postID, err)
+	}
+
+	enclosure, err := s.DB.GetEpisodeEnclosure(ctx, postID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Post{}, database.PostEnclosure{}, ErrNoEpisode
+	}
+	if err != nil {
+		return database.Post{}, database.PostEnclosure{}, fmt.Errorf("failed to get enclosure: %w", err)
+	}
+	return post, enclosure, nil
+}
@@ -0,0 +1,76 @@
+package posts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+// GetPost fetches a single post by ID
+func (s *Service) GetPost(ctx context.Context, postID uuid.UUID) (database.Post, error) {
+	post, err := s.DB.GetPost(ctx, postID)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("post %s not found: %w", postID, err)
+	}
+	return post, nil
+}
+
+// MarkRead marks a post as read for a user
+func (s *Service) MarkRead(ctx context.Context, userID, postID uuid.UUID) (database.Post, error) {
+	post, err := s.GetPost(ctx, postID)
+	if err != nil {
+		return database.Post{}, err
+	}
+
+	params := database.MarkPostReadParams{
+		UserID: userID,
+		PostID: postID,
+	}
+
+	if err := s.DB.MarkPostRead(ctx, params); err != nil {
+		return database.Post{}, fmt.Errorf("failed to mark post as read: %w", err)
+	}
+	return post, nil
+}
+
+// MarkUnread marks a post as unread for a user
+func (s *Service) MarkUnread(ctx context.Context, userID, postID uuid.UUID) (database.Post, error) {
+	post, err := s.GetPost(ctx, postID)
+	if err != nil {
+		return database.Post{}, err
+	}
+
+	params := database.MarkPostUnreadParams{
+		UserID: userID,
+		PostID: postID,
+	}
+
+	if _, err := s.DB.MarkPostUnread(ctx, params); err != nil {
+		return database.Post{}, fmt.Errorf("failed to mark post as unread: %w", err)
+	}
+	return post, nil
+}
+
+// MarkAllRead marks every post in the user's followed feeds as read, or only
+// the posts of one feed when feedURL is not empty. It returns the number of
+// posts newly marked as read.
+func (s *Service) MarkAllRead(ctx context.Context, userID uuid.UUID, feedURL string) (int64, error) {
+	var count int64
+	var err error
+
+	if feedURL == "" {
+		count, err = s.DB.MarkAllPostsRead(ctx, userID)
+	} else {
+		count, err = s.DB.MarkFeedPostsRead(ctx, database.MarkFeedPostsReadParams{
+			UserID: userID,
+			Url:    feedURL,
+		})
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark posts as read: %w", err)
+	}
+
+	return count, nil
+}
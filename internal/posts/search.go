@@ -0,0 +1,34 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+// SearchPosts runs a full-text search over post titles and descriptions,
+// ranking the best matches first. Unless allFeeds is set, only posts from
+// the user's followed feeds are searched.
+func (s *Service) SearchPosts(ctx context.Context, userID uuid.UUID, query string, allFeeds bool, limit int32) ([]database.SearchPostsRow, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query is required")
+	}
+
+	params := database.SearchPostsParams{
+		Query:       query,
+		AllFeeds:    allFeeds,
+		UserID:      userID,
+		ResultLimit: limit,
+	}
+
+	results, err := s.DB.SearchPosts(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+	return results, nil
+}
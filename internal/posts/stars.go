@@ -0,0 +1,63 @@
+package posts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/google/uuid"
+)
+
+// ErrNotStarred is returned when unstarring a post the user hasn't starred
+var ErrNotStarred = errors.New("post is not starred")
+
+// StarPost saves a copy of a post to the user's starred posts
+func (s *Service) StarPost(ctx context.Context, userID, postID uuid.UUID) (database.Post, error) {
+	post, err := s.GetPost(ctx, postID)
+	if err != nil {
+		return database.Post{}, err
+	}
+
+	params := database.StarPostParams{
+		UserID: userID,
+		ID:     postID,
+	}
+
+	if err := s.DB.StarPost(ctx, params); err != nil {
+		return database.Post{}, fmt.Errorf("failed to star post: %w", err)
+	}
+	return post, nil
+}
+
+// UnstarPost removes a post from the user's starred posts, even if the
+// original post no longer exists
+func (s *Service) UnstarPost(ctx context.Context, userID, postID uuid.UUID) error {
+	params := database.UnstarPostParams{
+		UserID: userID,
+		PostID: postID,
+	}
+
+	count, err := s.DB.UnstarPost(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to unstar post: %w", err)
+	}
+	if count == 0 {
+		return ErrNotStarred
+	}
+	return nil
+}
+
+// GetStarredPosts fetches the user's starred posts, most recently starred first
+func (s *Service) GetStarredPosts(ctx context.Context, userID uuid.UUID, limit int32) ([]database.StarredPost, error) {
+	params := database.GetStarredPostsParams{
+		UserID: userID,
+		Limit:  limit,
+	}
+
+	starred, err := s.DB.GetStarredPosts(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get starred posts: %w", err)
+	}
+	return starred, nil
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestArticleText(t *testing.T) {
+	text := func(s string) pgtype.Text { return pgtype.Text{String: s, Valid: true} }
+
+	tests := []struct {
+		name string
+		post database.Post
+		want string
+	}{
+		{
+			name: "prefers the fetched article",
+			post: database.Post{Article: text("<p>Article</p>"), Content: text("<p>Content</p>"), Description: text("Summary")},
+			want: "Article",
+		},
+		{
+			name: "falls back to content",
+			post: database.Post{Content: text("<p>Content</p>"), Description: text("Summary")},
+			want: "Content",
+		},
+		{
+			name: "falls back to description",
+			post: database.Post{Description: text("A <b>short</b> summary")},
+			want: "A short summary",
+		},
+		{
+			name: "no body",
+			post: database.Post{},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := posts.ArticleText(tt.post, 0); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchMissingArticles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><nav>Menu</nav><article><h1>Title</h1>
+<p>The first paragraph of the article, long enough to be taken as its body text.</p>
+<p>A second paragraph, with a few more words in it, so it reads like an article.</p>
+<p>And a third paragraph to round out the article and settle any doubt at all.</p>
+</article></body></html>`))
+	}))
+	defer server.Close()
+
+	feedID, postID := uuid.New(), uuid.New()
+	db := &dbtest.DB{Rows: map[string][][]any{
+		"GetPostsMissingArticle": {{postID, server.URL + "/post"}},
+	}}
+	service := posts.NewService(dbtest.New(db))
+
+	// The feed's own context has run out by the time articles are fetched
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx = context.WithoutCancel(ctx)
+
+	pending, err := service.PostsMissingArticle(ctx, feedID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pending) != 1 || pending[0].ID != postID {
+		t.Fatalf("Expected the post missing its article, got %+v", pending)
+	}
+	if args := db.Args["GetPostsMissingArticle"][0]; args[0] != feedID {
+		t.Errorf("Expected posts for feed %s, got %v", feedID, args[0])
+	}
+
+	if err := service.FetchArticle(ctx, pending[0].ID, pending[0].Url); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	saved := db.Args["SetPostArticle"]
+	if len(saved) != 1 || saved[0][0] != postID {
+		t.Fatalf("Expected the article to be saved for the post, got %v", saved)
+	}
+	if article := saved[0][1].(pgtype.Text).String; !strings.Contains(article, "first paragraph") || strings.Contains(article, "Menu") {
+		t.Errorf("Expected only the article to be saved, got %q", article)
+	}
+}
@@ -0,0 +1,275 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCursor(t *testing.T) {
+	id := uuid.MustParse("6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a")
+	cursor := posts.Cursor{At: time.Date(2024, 1, 2, 3, 4, 5, 123000, time.UTC), ID: id}
+
+	parsed, err := posts.ParseCursor(cursor.String())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !parsed.At.Equal(cursor.At) || parsed.ID != id {
+		t.Errorf("Expected cursor to round-trip, got %+v", parsed)
+	}
+
+	for _, invalid := range []string{"", "2024-01-02T03:04:05", "yesterday," + id.String(), "2024-01-02T03:04:05,not-a-uuid"} {
+		if _, err := posts.ParseCursor(invalid); err == nil {
+			t.Errorf("Expected error for cursor %q", invalid)
+		}
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "2026-01-01", expected: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "7d", expected: now.AddDate(0, 0, -7)},
+		{value: "2w", expected: now.AddDate(0, 0, -14)},
+		{value: "36h", expected: now.Add(-36 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := posts.ParseTimeBound(tt.value, now)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := posts.ParseTimeBound("last week", now); err == nil {
+		t.Error("Expected error for invalid time bound")
+	}
+}
+
+func TestParseBrowseArgs(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Defaults", func(t *testing.T) {
+		opts, err := posts.ParseBrowseArgs(nil, now)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if opts.Limit != 10 || opts.OldestFirst || opts.Cursor != nil {
+			t.Errorf("Unexpected default options: %+v", opts)
+		}
+	})
+
+	t.Run("Limit and filters", func(t *testing.T) {
+		opts, err := posts.ParseBrowseArgs([]string{"25", "--unread", "--feed", "Go Blog", "--since", "7d", "--sort", "oldest", "--page", "3"}, now)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if opts.Limit != 25 || !opts.UnreadOnly || opts.Feed != "Go Blog" || !opts.OldestFirst || opts.Page != 3 {
+			t.Errorf("Unexpected options: %+v", opts)
+		}
+		if !opts.Since.Equal(now.AddDate(0, 0, -7)) {
+			t.Errorf("Expected since to be 7 days ago, got %s", opts.Since)
+		}
+	})
+
+	t.Run("After cursor sorts oldest first", func(t *testing.T) {
+		opts, err := posts.ParseBrowseArgs([]string{"--after", "2024-01-01T00:00:00,6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a"}, now)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !opts.OldestFirst || opts.Cursor == nil {
+			t.Errorf("Expected oldest-first options with a cursor, got %+v", opts)
+		}
+	})
+
+	invalid := [][]string{
+		{"--feed"},
+		{"--sort", "random"},
+		{"--page", "0"},
+		{"--verbose", "yes"},
+		{"--before", "2024-01-01T00:00:00,6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a", "--sort", "oldest"},
+		{"--before", "2024-01-01T00:00:00,6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a", "--page", "2"},
+	}
+	for _, args := range invalid {
+		if _, err := posts.ParseBrowseArgs(args, now); err == nil {
+			t.Errorf("Expected error for args %v", args)
+		}
+	}
+}
+
+// browsePosts answers GetPostsForUser from posts sorted newest first,
+// honouring the cursor, limit and offset as the query does
+func browsePosts(posts []database.GetPostsForUserRow) func(string, []any) [][]any {
+	return func(name string, args []any) [][]any {
+		if name != "GetPostsForUser" {
+			return nil
+		}
+		cursorAt := args[5].(pgtype.Timestamp)
+		cursorID := args[6].(uuid.UUID)
+		limit, offset := int(args[7].(int32)), int(args[8].(int32))
+
+		rows := [][]any{}
+		for _, p := range posts {
+			if cursorAt.Valid && !p.SortAt.Time.Before(cursorAt.Time) &&
+				!(p.SortAt.Time.Equal(cursorAt.Time) && bytes.Compare(p.ID[:], cursorID[:]) < 0) {
+				continue
+			}
+			if offset > 0 {
+				offset--
+				continue
+			}
+			if len(rows) == limit {
+				break
+			}
+			rows = append(rows, dbtest.PostsForUserRow(p))
+		}
+		return rows
+	}
+}
+
+// hideRule is a GetFilterRulesForUser row hiding posts with the keyword
+func hideRule(keyword string) []any {
+	return []any{uuid.New(), uuid.New(), pgtype.UUID{}, pgtype.Text{},
+		pgtype.Text{String: keyword, Valid: true}, posts.FilterHide, pgtype.Timestamp{}, pgtype.Text{}}
+}
+
+func TestBrowsePostsWithHideRules(t *testing.T) {
+	// Every other post is sponsored, newest first
+	start := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	feedID := uuid.New()
+	var all []database.GetPostsForUserRow
+	for i := range 40 {
+		title := fmt.Sprintf("Post %d", i)
+		if i%2 == 1 {
+			title += " (sponsored)"
+		}
+		at := pgtype.Timestamp{Time: start.Add(-time.Duration(i) * time.Hour), Valid: true}
+		all = append(all, database.GetPostsForUserRow{ID: uuid.New(), Title: title, FeedID: feedID, PublishedAt: at, SortAt: at})
+	}
+
+	t.Run("Pages don't repeat posts", func(t *testing.T) {
+		db := &dbtest.DB{
+			Rows:   map[string][][]any{"GetFilterRulesForUser": {hideRule("sponsored")}},
+			Answer: browsePosts(all),
+		}
+		service := posts.NewService(dbtest.New(db))
+
+		seen := make(map[uuid.UUID]bool)
+		for page := 1; page <= 3; page++ {
+			got, err := service.BrowsePosts(context.Background(), uuid.New(), posts.BrowseOptions{Limit: 5, Page: page})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(got) != 5 {
+				t.Fatalf("Expected 5 posts on page %d, got %d", page, len(got))
+			}
+			for i, post := range got {
+				if want := all[((page-1)*5+i)*2].ID; post.ID != want {
+					t.Errorf("Page %d post %d: expected %s, got %q", page, i, want, post.Title)
+				}
+				if seen[post.ID] {
+					t.Errorf("Post %q repeated on page %d", post.Title, page)
+				}
+				seen[post.ID] = true
+			}
+		}
+	})
+
+	t.Run("Cursor continues after the last post shown", func(t *testing.T) {
+		db := &dbtest.DB{
+			Rows:   map[string][][]any{"GetFilterRulesForUser": {hideRule("sponsored")}},
+			Answer: browsePosts(all),
+		}
+		service := posts.NewService(dbtest.New(db))
+
+		first, err := service.BrowsePosts(context.Background(), uuid.New(), posts.BrowseOptions{Limit: 3})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		next, err := service.BrowsePosts(context.Background(), uuid.New(), posts.BrowseOptions{Limit: 3, Cursor: posts.NextCursor(first)})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(next) != 3 || next[0].ID != all[6].ID {
+			t.Errorf("Expected the next page to start at %q, got %d posts", all[6].Title, len(next))
+		}
+	})
+
+	t.Run("Long runs of hidden posts use a bounded number of queries", func(t *testing.T) {
+		db := &dbtest.DB{
+			Rows:   map[string][][]any{"GetFilterRulesForUser": {hideRule("post")}},
+			Answer: browsePosts(append(slices.Repeat(all, 10), all...)),
+		}
+		service := posts.NewService(dbtest.New(db))
+
+		got, err := service.BrowsePosts(context.Background(), uuid.New(), posts.BrowseOptions{Limit: 5})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("Expected every post to be hidden, got %d", len(got))
+		}
+		if queries := db.Count("GetPostsForUser"); queries > 10 {
+			t.Errorf("Expected at most 10 queries, got %d", queries)
+		}
+		for _, args := range db.Args["GetPostsForUser"] {
+			if limit := args[7].(int32); limit != 5 {
+				t.Errorf("Expected every batch to read 5 posts, got %d", limit)
+			}
+		}
+	})
+}
+
+func TestWritePostBlocks(t *testing.T) {
+	feedID := uuid.New()
+	post := database.GetPostsForUserRow{
+		ID:          uuid.New(),
+		Title:       "Go 1.24 released",
+		Url:         "https://go.dev/blog/go1.24",
+		Description: pgtype.Text{String: `<p>Read the <a href="https://go.dev/doc/go1.24">release notes</a>.</p>`, Valid: true},
+		PublishedAt: pgtype.Timestamp{Time: time.Date(2025, 2, 11, 17, 0, 0, 0, time.UTC), Valid: true},
+		FeedID:      feedID,
+		Authors:     []string{"Go Team"},
+		FeedName:    "Go Blog",
+	}
+	enclosures := map[uuid.UUID][]database.PostEnclosure{post.ID: {{Url: "https://go.dev/go1.24.mp3"}}}
+	filters := posts.Filters{{Action: posts.FilterHighlight, Keyword: "release"}}
+
+	var out bytes.Buffer
+	if err := posts.WritePostBlocks(&out, []database.GetPostsForUserRow{post}, enclosures, filters, 80); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := fmt.Sprintf(`* Go 1.24 released
+Go Blog | 2025-02-11 17:00:00 | unread
+https://go.dev/blog/go1.24
+ID: %s
+Authors: Go Team
+Enclosure: https://go.dev/go1.24.mp3
+
+Read the release notes[1].
+
+[1] https://go.dev/doc/go1.24
+`, post.ID)
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/posts"
+)
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "Unchanged", raw: "https://example.com/post/1", want: "https://example.com/post/1"},
+		{name: "Trailing slash", raw: "https://example.com/post/1/", want: "https://example.com/post/1"},
+		{name: "Root path", raw: "https://example.com/", want: "https://example.com"},
+		{name: "Fragment", raw: "https://example.com/post#comments", want: "https://example.com/post"},
+		{name: "Tracking parameters", raw: "https://example.com/post?utm_source=rss&UTM_Medium=feed&id=7", want: "https://example.com/post?id=7"},
+		{name: "Only tracking parameters", raw: "https://example.com/post/?utm_campaign=x", want: "https://example.com/post"},
+		{name: "Sorted query", raw: "https://example.com/post?b=2&a=1", want: "https://example.com/post?a=1&b=2"},
+		{name: "Case and default port", raw: "HTTPS://Example.COM:443/Post", want: "https://example.com/Post"},
+		{name: "Non-default port", raw: "http://example.com:8080/post", want: "http://example.com:8080/post"},
+		{name: "Surrounding whitespace", raw: "  https://example.com/post  ", want: "https://example.com/post"},
+		{name: "Not a URL", raw: "not a url", want: "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := posts.CanonicalizeURL(tt.raw); got != tt.want {
+				t.Errorf("CanonicalizeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
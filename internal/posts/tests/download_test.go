@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/posts"
+)
+
+// episode is the file served by the download tests
+var episode = []byte(strings.Repeat("0123456789", 1000))
+
+// newEpisodeServer serves episode with Range support and records the Range
+// header of each request
+func newEpisodeServer(t *testing.T, ranges *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "episode.mp3", time.Time{}, bytes.NewReader(episode))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadFile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Fresh download", func(t *testing.T) {
+		var ranges []string
+		server := newEpisodeServer(t, &ranges)
+		path := filepath.Join(t.TempDir(), "episode.mp3")
+
+		result, err := posts.DownloadFile(ctx, server.Client(), server.URL, path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Bytes != int64(len(episode)) || result.ResumedFrom != 0 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+		if ranges[0] != "" {
+			t.Errorf("Expected no Range header, got %s", ranges[0])
+		}
+		assertFile(t, path, episode)
+	})
+
+	t.Run("Resume partial download", func(t *testing.T) {
+		var ranges []string
+		server := newEpisodeServer(t, &ranges)
+		path := filepath.Join(t.TempDir(), "episode.mp3")
+		if err := os.WriteFile(path+".part", episode[:4000], 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := posts.DownloadFile(ctx, server.Client(), server.URL, path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if ranges[0] != "bytes=4000-" {
+			t.Errorf("Expected Range bytes=4000-, got %q", ranges[0])
+		}
+		if result.Bytes != int64(len(episode)) || result.ResumedFrom != 4000 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+		assertFile(t, path, episode)
+		if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+			t.Errorf("Expected partial file to be gone, got %v", err)
+		}
+	})
+
+	t.Run("Partial file already complete", func(t *testing.T) {
+		var ranges []string
+		server := newEpisodeServer(t, &ranges)
+		path := filepath.Join(t.TempDir(), "episode.mp3")
+		if err := os.WriteFile(path+".part", episode, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := posts.DownloadFile(ctx, server.Client(), server.URL, path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(ranges) != 1 || result.Bytes != int64(len(episode)) {
+			t.Errorf("Expected one request and the full file, got %d requests and %+v", len(ranges), result)
+		}
+		assertFile(t, path, episode)
+	})
+
+	t.Run("Stale partial file larger than the episode", func(t *testing.T) {
+		var ranges []string
+		server := newEpisodeServer(t, &ranges)
+		path := filepath.Join(t.TempDir(), "episode.mp3")
+		if err := os.WriteFile(path+".part", append(episode, "extra"...), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := posts.DownloadFile(ctx, server.Client(), server.URL, path); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(ranges) != 2 || ranges[1] != "" {
+			t.Errorf("Expected a restart without Range, got %q", ranges)
+		}
+		assertFile(t, path, episode)
+	})
+
+	t.Run("Server without Range support", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(episode)
+		}))
+		defer server.Close()
+		path := filepath.Join(t.TempDir(), "episode.mp3")
+		if err := os.WriteFile(path+".part", []byte("garbage"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := posts.DownloadFile(ctx, server.Client(), server.URL, path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.ResumedFrom != 0 {
+			t.Errorf("Expected download to start over, got %+v", result)
+		}
+		assertFile(t, path, episode)
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		path := filepath.Join(t.TempDir(), "episode.mp3")
+
+		if _, err := posts.DownloadFile(ctx, server.Client(), server.URL, path); err == nil {
+			t.Fatal("Expected error for status 404, got nil")
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected no file, got %v", err)
+		}
+	})
+}
+
+// assertFile checks that the file at path holds want
+func assertFile(t *testing.T, path string, want []byte) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read download: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Downloaded %d bytes that don't match the %d byte episode", len(got), len(want))
+	}
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParseFilterArgs(t *testing.T) {
+	opts, err := posts.ParseFilterArgs([]string{"--feed", "https://example.org/feed", "--title-regex", "(?i)sponsored", "--action", "hide"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if opts.FeedURL != "https://example.org/feed" || opts.TitleRegex != "(?i)sponsored" || opts.Action != posts.FilterHide {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	invalid := [][]string{
+		{"--action", "hide"},
+		{"--keyword", "postgres"},
+		{"--keyword", "postgres", "--action", "delete"},
+		{"--keyword", "postgres", "--action"},
+		{"--keyword", "postgres", "--colour", "red", "--action", "star"},
+	}
+	for _, args := range invalid {
+		if _, err := posts.ParseFilterArgs(args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+func TestCompileFilterInvalidRegex(t *testing.T) {
+	rule := database.FilterRule{TitleRegex: pgtype.Text{String: "(unclosed", Valid: true}, Action: posts.FilterHide}
+	if _, err := posts.CompileFilter(rule); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
+
+func TestFiltersMatch(t *testing.T) {
+	feedID := uuid.New()
+	otherFeedID := uuid.New()
+
+	compile := func(rule database.FilterRule) posts.Filter {
+		t.Helper()
+		filter, err := posts.CompileFilter(rule)
+		if err != nil {
+			t.Fatalf("Failed to compile filter: %v", err)
+		}
+		return filter
+	}
+	filters := posts.Filters{
+		compile(database.FilterRule{
+			FeedID:     pgtype.UUID{Bytes: feedID, Valid: true},
+			TitleRegex: pgtype.Text{String: `(?i)\bsponsored\b`, Valid: true},
+			Action:     posts.FilterHide,
+		}),
+		compile(database.FilterRule{
+			Keyword: pgtype.Text{String: "Postgres", Valid: true},
+			Action:  posts.FilterHighlight,
+		}),
+	}
+
+	tests := []struct {
+		name        string
+		action      string
+		feedID      uuid.UUID
+		title       string
+		description string
+		want        bool
+	}{
+		{name: "regex matches title", action: posts.FilterHide, feedID: feedID, title: "Sponsored: buy now", want: true},
+		{name: "regex rule is limited to its feed", action: posts.FilterHide, feedID: otherFeedID, title: "Sponsored: buy now", want: false},
+		{name: "regex doesn't match", action: posts.FilterHide, feedID: feedID, title: "Unsponsored thoughts", want: false},
+		{name: "keyword in title ignores case", action: posts.FilterHighlight, feedID: otherFeedID, title: "Why POSTGRES wins", want: true},
+		{name: "keyword in description text", action: posts.FilterHighlight, feedID: feedID, title: "Databases", description: "<p>All about <b>PostgreSQL</b></p>", want: true},
+		{name: "keyword isn't matched in markup", action: posts.FilterHighlight, feedID: feedID, title: "Databases", description: `<a href="https://postgres.example">MySQL</a>`, want: false},
+		{name: "other actions don't match", action: posts.FilterStar, feedID: feedID, title: "Postgres", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filters.Match(tt.action, tt.feedID, tt.title, tt.description); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
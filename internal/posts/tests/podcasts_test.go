@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParseEpisodeDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "3600", want: time.Hour},
+		{value: "45:10", want: 45*time.Minute + 10*time.Second},
+		{value: "1:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{value: " 90.5 ", want: 90*time.Second + 500*time.Millisecond},
+		{value: "", wantErr: true},
+		{value: "1:75", wantErr: true},
+		{value: "1.5:00", wantErr: true},
+		{value: "1:2:3:4", wantErr: true},
+		{value: "an hour", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := posts.ParseEpisodeDuration(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatEpisodeDuration(t *testing.T) {
+	tests := []struct {
+		seconds pgtype.Int4
+		want    any
+	}{
+		{seconds: pgtype.Int4{}, want: nil},
+		{seconds: pgtype.Int4{Int32: 59, Valid: true}, want: "0:59"},
+		{seconds: pgtype.Int4{Int32: 2710, Valid: true}, want: "45:10"},
+		{seconds: pgtype.Int4{Int32: 3723, Valid: true}, want: "1:02:03"},
+	}
+
+	for _, tt := range tests {
+		if got := posts.FormatEpisodeDuration(tt.seconds); got != tt.want {
+			t.Errorf("FormatEpisodeDuration(%v) = %v, want %v", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestEpisodeFileName(t *testing.T) {
+	post := database.Post{ID: uuid.MustParse("6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a")}
+
+	tests := []struct {
+		name      string
+		enclosure database.PostEnclosure
+		want      string
+	}{
+		{
+			name:      "Name from URL",
+			enclosure: database.PostEnclosure{Url: "https://cdn.example.com/shows/episode-12.mp3?token=abc"},
+			want:      "6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a-episode-12.mp3",
+		},
+		{
+			name:      "Escaped name",
+			enclosure: database.PostEnclosure{Url: "https://cdn.example.com/My%20Episode.m4a"},
+			want:      "6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a-My Episode.m4a",
+		},
+		{
+			name:      "No name in URL",
+			enclosure: database.PostEnclosure{Url: "https://cdn.example.com/download?id=12", Type: pgtype.Text{String: "audio/mpeg", Valid: true}},
+			want:      "6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a.mp3",
+		},
+		{
+			name:      "Unknown type",
+			enclosure: database.PostEnclosure{Url: "https://cdn.example.com/"},
+			want:      "6f1c2a9e-0b7d-4a35-9a51-2f0c1d8e4b3a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := posts.EpisodeFileName(post, tt.enclosure); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	// Hosts often serve every episode under the same name
+	other := database.Post{ID: uuid.MustParse("0b6e7c43-2f55-4d7a-8f0e-8a3c1f2b9d10")}
+	enclosure := database.PostEnclosure{Url: "https://cdn.example.com/media.mp3"}
+	if posts.EpisodeFileName(post, enclosure) == posts.EpisodeFileName(other, enclosure) {
+		t.Error("Expected different episodes to get different file names")
+	}
+}
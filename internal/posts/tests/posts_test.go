@@ -1,7 +1,16 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/abahnj/rssagg/internal/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func TestParseRSSTime(t *testing.T) {
@@ -14,7 +23,61 @@ func TestParseRSSTime(t *testing.T) {
 }
 
 func TestCreatePost(t *testing.T) {
-	t.Skip("Skipping until test mocks are implemented")
+	feed := database.Feed{ID: uuid.New(), Name: "Go Time"}
+	item := types.RSSItem{
+		Title:      "Episode 1",
+		Link:       "https://example.com/episodes/1",
+		Enclosures: []types.RSSEnclosure{{URL: "https://example.com/1.mp3", Type: "audio/mpeg", Length: "1024"}},
+	}
+	saved := []any{uuid.New(), pgtype.Timestamp{}, pgtype.Timestamp{}, item.Title, item.Link, pgtype.Text{},
+		pgtype.Timestamp{}, feed.ID, pgtype.Text{}, item.Link, pgtype.UUID{}, pgtype.Text{},
+		[]string{}, []string{}, pgtype.Int4{}, pgtype.Int4{}, pgtype.Text{}, pgtype.Text{}}
+
+	t.Run("Saves the post with its enclosures", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"CreatePost": {saved}}}
+		service := posts.NewService(dbtest.New(db))
+
+		result := service.CreatePost(context.Background(), feed, item)
+		if result.Err != nil || !result.Created {
+			t.Fatalf("Expected the post to be created, got %+v", result)
+		}
+		if args := db.Args["CreatePostEnclosure"]; len(args) != 1 || args[0][0] != result.PostID {
+			t.Errorf("Expected the enclosure to be saved with the post, got %v", args)
+		}
+		if db.Tx == nil || !db.Tx.Committed {
+			t.Error("Expected the post and enclosures to be committed together")
+		}
+	})
+
+	t.Run("A failed enclosure saves nothing", func(t *testing.T) {
+		db := &dbtest.DB{
+			Rows: map[string][][]any{"CreatePost": {saved}},
+			Errs: map[string]error{"CreatePostEnclosure": errors.New("connection reset")},
+		}
+		service := posts.NewService(dbtest.New(db))
+
+		result := service.CreatePost(context.Background(), feed, item)
+		if result.Err == nil || result.Created {
+			t.Fatalf("Expected the post not to be created, got %+v", result)
+		}
+		if db.Tx == nil || db.Tx.Committed || !db.Tx.RolledBack {
+			t.Error("Expected the post to be rolled back")
+		}
+	})
+
+	t.Run("Existing posts are skipped", func(t *testing.T) {
+		// The insert returns no row when the feed already has the post
+		db := &dbtest.DB{}
+		service := posts.NewService(dbtest.New(db))
+
+		result := service.CreatePost(context.Background(), feed, item)
+		if result.Err != nil || result.Created {
+			t.Fatalf("Expected the post to be skipped, got %+v", result)
+		}
+		if db.Count("CreatePostEnclosure") != 0 {
+			t.Error("Expected no enclosures to be saved")
+		}
+	})
 }
 
 func TestGetPostsForUser(t *testing.T) {
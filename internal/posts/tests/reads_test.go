@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestMarkRead(t *testing.T) {
+	userID := uuid.New()
+	post := database.Post{ID: uuid.New(), Title: "Go 1.24 is released", Url: "https://go.dev/blog/go1.24"}
+
+	t.Run("Marks the post read for the user", func(t *testing.T) {
+		db := &dbtest.DB{Rows: map[string][][]any{"GetPost": {dbtest.PostRow(post)}}}
+		service := posts.NewService(dbtest.New(db))
+
+		got, err := service.MarkRead(context.Background(), userID, post.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got.ID != post.ID || got.Title != post.Title {
+			t.Errorf("Expected the post to be returned, got %+v", got)
+		}
+		if db.Count("MarkPostRead") != 1 {
+			t.Fatalf("Expected the post to be marked read once, got %d", db.Count("MarkPostRead"))
+		}
+		if args := db.Args["MarkPostRead"][0]; args[0] != userID || args[1] != post.ID {
+			t.Errorf("Expected the read to be recorded for the user and post, got %v", args)
+		}
+	})
+
+	t.Run("Unknown posts aren't marked", func(t *testing.T) {
+		db := &dbtest.DB{}
+		service := posts.NewService(dbtest.New(db))
+
+		if _, err := service.MarkRead(context.Background(), userID, post.ID); err == nil {
+			t.Fatal("Expected an error for an unknown post")
+		}
+		if db.Count("MarkPostRead") != 0 {
+			t.Error("Expected no read to be recorded")
+		}
+	})
+}
+
+func TestMarkUnread(t *testing.T) {
+	userID := uuid.New()
+	post := database.Post{ID: uuid.New(), Title: "Go 1.24 is released"}
+	db := &dbtest.DB{
+		Rows: map[string][][]any{"GetPost": {dbtest.PostRow(post)}},
+		Tags: map[string]string{"MarkPostUnread": "DELETE 1"},
+	}
+	service := posts.NewService(dbtest.New(db))
+
+	if _, err := service.MarkUnread(context.Background(), userID, post.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if db.Count("MarkPostUnread") != 1 {
+		t.Fatalf("Expected the post to be marked unread once, got %d", db.Count("MarkPostUnread"))
+	}
+	if args := db.Args["MarkPostUnread"][0]; args[0] != userID || args[1] != post.ID {
+		t.Errorf("Expected the read to be removed for the user and post, got %v", args)
+	}
+}
+
+func TestMarkAllRead(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("Every followed feed", func(t *testing.T) {
+		db := &dbtest.DB{Tags: map[string]string{"MarkAllPostsRead": "INSERT 0 12"}}
+		service := posts.NewService(dbtest.New(db))
+
+		count, err := service.MarkAllRead(context.Background(), userID, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count != 12 {
+			t.Errorf("Expected 12 posts marked read, got %d", count)
+		}
+		if db.Count("MarkFeedPostsRead") != 0 {
+			t.Error("Expected posts in every feed to be marked, not just one")
+		}
+		if args := db.Args["MarkAllPostsRead"][0]; args[0] != userID {
+			t.Errorf("Expected posts to be marked for the user, got %v", args)
+		}
+	})
+
+	t.Run("One feed", func(t *testing.T) {
+		feedURL := "https://go.dev/blog/feed.atom"
+		db := &dbtest.DB{Tags: map[string]string{"MarkFeedPostsRead": "INSERT 0 3"}}
+		service := posts.NewService(dbtest.New(db))
+
+		count, err := service.MarkAllRead(context.Background(), userID, feedURL)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 posts marked read, got %d", count)
+		}
+		if db.Count("MarkAllPostsRead") != 0 {
+			t.Error("Expected only the feed's posts to be marked")
+		}
+		if args := db.Args["MarkFeedPostsRead"][0]; args[0] != userID || args[1] != feedURL {
+			t.Errorf("Expected posts to be marked for the user and feed, got %v", args)
+		}
+	})
+
+	t.Run("Nothing left unread", func(t *testing.T) {
+		db := &dbtest.DB{Tags: map[string]string{"MarkAllPostsRead": "INSERT 0 0"}}
+		service := posts.NewService(dbtest.New(db))
+
+		count, err := service.MarkAllRead(context.Background(), userID, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count != 0 {
+			t.Errorf("Expected no posts marked read, got %d", count)
+		}
+	})
+}
+
+func TestUnreadOnly(t *testing.T) {
+	userID := uuid.New()
+	at := pgtype.Timestamp{Time: time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC), Valid: true}
+	read := database.GetPostsForUserRow{ID: uuid.New(), Title: "Read post", SortAt: at, IsRead: true}
+	unread := database.GetPostsForUserRow{ID: uuid.New(), Title: "Unread post", SortAt: at}
+
+	// Answer like the query: read posts are left out when only unread ones are asked for
+	answer := func(name string, args []any) [][]any {
+		if name != "GetPostsForUser" {
+			return nil
+		}
+		rows := browsePosts([]database.GetPostsForUserRow{unread})(name, args)
+		if !args[1].(bool) {
+			rows = browsePosts([]database.GetPostsForUserRow{read, unread})(name, args)
+		}
+		return rows
+	}
+
+	t.Run("Unread posts only", func(t *testing.T) {
+		db := &dbtest.DB{Answer: answer}
+		service := posts.NewService(dbtest.New(db))
+
+		got, err := service.GetUnreadPostsForUser(context.Background(), userID, 10)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if args := db.Args["GetPostsForUser"][0]; args[0] != userID || args[1] != true {
+			t.Errorf("Expected an unread-only query for the user, got %v", args)
+		}
+		if len(got) != 1 || got[0].ID != unread.ID || got[0].IsRead {
+			t.Errorf("Expected only the unread post, got %+v", got)
+		}
+	})
+
+	t.Run("Read posts are included by default", func(t *testing.T) {
+		db := &dbtest.DB{Answer: answer}
+		service := posts.NewService(dbtest.New(db))
+
+		got, err := service.BrowsePosts(context.Background(), userID, posts.BrowseOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if args := db.Args["GetPostsForUser"][0]; args[1] != false {
+			t.Errorf("Expected read posts to be included, got %v", args[1])
+		}
+		if len(got) != 2 || !got[0].IsRead || got[1].IsRead {
+			t.Errorf("Expected the read post to be marked read, got %+v", got)
+		}
+	})
+}
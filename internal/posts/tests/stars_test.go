@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/cli"
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/dbtest"
+	"github.com/abahnj/rssagg/internal/posts"
+	"github.com/google/uuid"
+)
+
+func TestHandlerStarredLimit(t *testing.T) {
+	user := database.User{ID: uuid.New(), Name: "alice"}
+
+	for _, limit := range []string{"0", "-5", "ten"} {
+		t.Run(limit, func(t *testing.T) {
+			db := &dbtest.DB{}
+			queries := dbtest.New(db)
+			state := &cli.State{Db: &queries, Out: &bytes.Buffer{}}
+
+			err := posts.HandlerStarred(state, cli.Command{Name: "starred", Args: []string{limit}}, user)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if db.Count("GetStarredPosts") != 0 {
+				t.Error("Expected no query for an invalid limit")
+			}
+		})
+	}
+
+	t.Run("Valid limit", func(t *testing.T) {
+		db := &dbtest.DB{}
+		queries := dbtest.New(db)
+		var out bytes.Buffer
+		state := &cli.State{Db: &queries, Out: &out}
+
+		if err := posts.HandlerStarred(state, cli.Command{Name: "starred", Args: []string{"5"}}, user); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if args := db.LastArgs("GetStarredPosts"); len(args) != 2 || args[1] != int32(5) {
+			t.Errorf("Expected a limit of 5, got %v", args)
+		}
+		if out.String() != "No starred posts\n" {
+			t.Errorf("Expected the empty message, got %q", out.String())
+		}
+	})
+}
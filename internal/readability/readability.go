@@ -0,0 +1,432 @@
+// Package readability extracts the main article from a web page with a
+// heuristic in the style of Arc90's Readability: paragraphs score their
+// parent blocks by length and punctuation, and the best block wins
+package readability
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrNoArticle is returned when a page has no block of text long enough to
+// be an article
+var ErrNoArticle = errors.New("no article found on page")
+
+// minArticleLength is the least text, in characters, an article can have
+const minArticleLength = 140
+
+// minParagraphLength is the least text a paragraph needs to add to its
+// parents' scores
+const minParagraphLength = 25
+
+// Article is the main content extracted from a page
+type Article struct {
+	Title string
+	// HTML is the article body, with relative links made absolute
+	HTML string
+}
+
+var (
+	// unlikelyCandidates matches the class or id of page furniture that
+	// is removed before scoring, unless maybeCandidate also matches
+	unlikelyCandidates = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|foot|header|legends|menu|modal|newsletter|pager|popup|related|remark|replies|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe`)
+	maybeCandidate     = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+
+	// positiveNames and negativeNames adjust the score of a block by its
+	// class and id
+	positiveNames = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeNames = regexp.MustCompile(`(?i)-ad-|hidden|banner|combx|comment|com-|contact|foot|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// removedTags are never part of an article
+var removedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Form: true, atom.Button: true, atom.Input: true,
+	atom.Select: true, atom.Textarea: true, atom.Nav: true, atom.Aside: true,
+	atom.Footer: true, atom.Svg: true, atom.Object: true, atom.Embed: true,
+	atom.Canvas: true, atom.Link: true, atom.Meta: true,
+}
+
+// blockTags are elements that stop a div from being treated as a paragraph
+var blockTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Div: true, atom.Dl: true, atom.Fieldset: true, atom.Figure: true,
+	atom.Footer: true, atom.Form: true, atom.H1: true, atom.H2: true,
+	atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Main: true, atom.Nav: true,
+	atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true,
+	atom.Table: true, atom.Ul: true,
+}
+
+// keptAttributes are the attributes left on elements in the extracted HTML
+var keptAttributes = map[string]bool{"href": true, "src": true, "alt": true, "title": true}
+
+// Extract finds the main article in an HTML page. pageURL resolves
+// relative links and image sources in the result
+func Extract(r io.Reader, pageURL *url.URL) (Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return Article{}, fmt.Errorf("error parsing HTML: %w", err)
+	}
+
+	article := Article{Title: pageTitle(doc)}
+
+	body := findElement(doc, atom.Body)
+	if body == nil {
+		return Article{}, ErrNoArticle
+	}
+	prune(body)
+
+	best := topCandidate(body)
+	if best == nil || utf8.RuneCountInString(innerText(best.node)) < minArticleLength {
+		return Article{}, ErrNoArticle
+	}
+
+	content := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, node := range withSiblings(best) {
+		node.Parent.RemoveChild(node)
+		content.AppendChild(node)
+	}
+	clean(content, pageURL)
+
+	var buf bytes.Buffer
+	for child := content.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&buf, child); err != nil {
+			return Article{}, fmt.Errorf("error rendering article: %w", err)
+		}
+	}
+	article.HTML = strings.TrimSpace(buf.String())
+	return article, nil
+}
+
+// candidate is a block that may hold the article, with the score its
+// paragraphs gave it
+type candidate struct {
+	node  *html.Node
+	score float64
+}
+
+// scores holds the candidates found while scoring a page
+type scores map[*html.Node]*candidate
+
+// topCandidate scores every block containing a paragraph and returns the
+// best one, with its score reduced by the share of its text that is links
+func topCandidate(body *html.Node) *candidate {
+	candidates := make(scores)
+
+	for _, paragraph := range paragraphs(body) {
+		text := innerText(paragraph)
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLength {
+			continue
+		}
+
+		// One point for the paragraph, one per comma and one per 100
+		// characters, up to 3
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(length/100), 3)
+
+		if parent := paragraph.Parent; parent != nil && parent.Type == html.ElementNode {
+			candidates.get(parent).score += score
+			if grandparent := parent.Parent; grandparent != nil && grandparent.Type == html.ElementNode {
+				candidates.get(grandparent).score += score / 2
+			}
+		}
+	}
+
+	var best *candidate
+	for _, c := range candidates {
+		c.score *= 1 - linkDensity(c.node)
+		if best == nil || c.score > best.score {
+			best = c
+		}
+	}
+	return best
+}
+
+// get returns the candidate for a node, starting it with a score for its
+// tag and its class and id
+func (s scores) get(node *html.Node) *candidate {
+	if c, ok := s[node]; ok {
+		return c
+	}
+
+	c := &candidate{node: node, score: classWeight(node)}
+	switch node.DataAtom {
+	case atom.Article:
+		c.score += 10
+	case atom.Div, atom.Main, atom.Section:
+		c.score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		c.score += 3
+	case atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		c.score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		c.score -= 5
+	}
+	s[node] = c
+	return c
+}
+
+// withSiblings returns the best candidate along with its siblings that
+// look like part of the same article, such as paragraphs split across
+// several blocks
+func withSiblings(best *candidate) []*html.Node {
+	parent := best.node.Parent
+	if parent == nil {
+		return []*html.Node{best.node}
+	}
+
+	threshold := math.Max(10, best.score*0.2)
+	var nodes []*html.Node
+	for sibling := parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling == best.node {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+
+		if siblingCandidate := topCandidate(sibling); siblingCandidate != nil && siblingCandidate.node == sibling && siblingCandidate.score >= threshold {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		if sibling.DataAtom == atom.P {
+			length := utf8.RuneCountInString(innerText(sibling))
+			if length > 80 && linkDensity(sibling) < 0.25 {
+				nodes = append(nodes, sibling)
+			}
+		}
+	}
+	return nodes
+}
+
+// paragraphs returns the elements whose text is scored: paragraphs,
+// preformatted text, table cells, quotes, and divs without block children
+func paragraphs(root *html.Node) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+				found = append(found, n)
+			case atom.Div:
+				if !hasBlockChild(n) {
+					found = append(found, n)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(root)
+	return found
+}
+
+// hasBlockChild reports whether any element inside n is a block
+func hasBlockChild(n *html.Node) bool {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && (blockTags[child.DataAtom] || hasBlockChild(child)) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes elements that can't be part of the article: scripts,
+// forms and navigation, hidden elements, and elements whose class or id
+// marks them as page furniture
+func prune(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if shouldRemove(child) {
+			n.RemoveChild(child)
+		} else {
+			prune(child)
+		}
+		child = next
+	}
+}
+
+// shouldRemove reports whether prune removes a node
+func shouldRemove(n *html.Node) bool {
+	switch n.Type {
+	case html.CommentNode:
+		return true
+	case html.ElementNode:
+	default:
+		return false
+	}
+
+	if removedTags[n.DataAtom] {
+		return true
+	}
+	if _, hidden := attr(n, "hidden"); hidden {
+		return true
+	}
+	if style, _ := attr(n, "style"); strings.Contains(strings.ReplaceAll(style, " ", ""), "display:none") {
+		return true
+	}
+
+	// Never drop the containers most likely to hold the article
+	if n.DataAtom == atom.Article || n.DataAtom == atom.Main || n.DataAtom == atom.Body || n.DataAtom == atom.A {
+		return false
+	}
+	names := classAndID(n)
+	return unlikelyCandidates.MatchString(names) && !maybeCandidate.MatchString(names)
+}
+
+// clean tidies the extracted article: link-heavy blocks like lists of
+// related posts are dropped, attributes other than links, sources and
+// alternative text are removed, and relative URLs are resolved
+func clean(n *html.Node, pageURL *url.URL) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.ElementNode {
+			switch child.DataAtom {
+			case atom.Div, atom.Section, atom.Ul, atom.Ol, atom.Table:
+				if linkDensity(child) > 0.5 || classWeight(child) < 0 && utf8.RuneCountInString(innerText(child)) < minArticleLength {
+					n.RemoveChild(child)
+					child = next
+					continue
+				}
+			}
+
+			attrs := child.Attr[:0]
+			for _, a := range child.Attr {
+				if !keptAttributes[a.Key] || a.Namespace != "" {
+					continue
+				}
+				if (a.Key == "href" || a.Key == "src") && pageURL != nil {
+					a.Val = resolve(pageURL, a.Val)
+				}
+				attrs = append(attrs, a)
+			}
+			child.Attr = attrs
+			clean(child, pageURL)
+		}
+		child = next
+	}
+}
+
+// resolve makes a URL absolute relative to the page
+func resolve(pageURL *url.URL, ref string) string {
+	parsed, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+	return pageURL.ResolveReference(parsed).String()
+}
+
+// classWeight scores an element's class and id: 25 points for names that
+// suggest content and -25 for names that suggest furniture
+func classWeight(n *html.Node) float64 {
+	var weight float64
+	for _, key := range []string{"class", "id"} {
+		value, _ := attr(n, key)
+		if value == "" {
+			continue
+		}
+		if negativeNames.MatchString(value) {
+			weight -= 25
+		}
+		if positiveNames.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity returns the share of an element's text that is inside links
+func linkDensity(n *html.Node) float64 {
+	length := utf8.RuneCountInString(innerText(n))
+	if length == 0 {
+		return 0
+	}
+
+	linkLength := 0
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.DataAtom == atom.A {
+			linkLength += utf8.RuneCountInString(innerText(node))
+			return
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return float64(linkLength) / float64(length)
+}
+
+// innerText returns the text inside a node with whitespace collapsed
+func innerText(n *html.Node) string {
+	var buf strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			buf.WriteString(node.Data)
+			buf.WriteByte(' ')
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// pageTitle returns the page's <title>, or its first <h1> if it has none
+func pageTitle(doc *html.Node) string {
+	if title := findElement(doc, atom.Title); title != nil {
+		if text := innerText(title); text != "" {
+			return text
+		}
+	}
+	if heading := findElement(doc, atom.H1); heading != nil {
+		return innerText(heading)
+	}
+	return ""
+}
+
+// findElement returns the first element with the given tag
+func findElement(n *html.Node, tag atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == tag {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// classAndID returns an element's class and id, for matching names
+func classAndID(n *html.Node) string {
+	class, _ := attr(n, "class")
+	id, _ := attr(n, "id")
+	return class + " " + id
+}
+
+// attr returns the value of an element's attribute and whether it has it
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key && a.Namespace == "" {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/readability"
+)
+
+func TestExtract(t *testing.T) {
+	file, err := os.Open("testdata/article.html")
+	if err != nil {
+		t.Fatalf("Failed to open test page: %v", err)
+	}
+	defer file.Close()
+
+	pageURL, _ := url.Parse("https://example.org/blog/channels")
+	article, err := readability.Extract(file, pageURL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if article.Title != "Why Go Channels Matter" {
+		t.Errorf("Expected page title, got %q", article.Title)
+	}
+
+	for _, want := range []string{
+		"Channels are the pipes",
+		"sends and receives block",
+		`href="https://example.org/docs/channels"`,
+		`src="https://example.org/blog/images/pipes.png"`,
+		"Buffered channels queue",
+	} {
+		if !strings.Contains(article.HTML, want) {
+			t.Errorf("Expected article to contain %q, got %s", want, article.HTML)
+		}
+	}
+
+	for _, unwanted := range []string{"newsletter", "Great post", "Copyright", "Archive", "tracking", "onclick", "class="} {
+		if strings.Contains(article.HTML, unwanted) {
+			t.Errorf("Expected article not to contain %q, got %s", unwanted, article.HTML)
+		}
+	}
+}
+
+func TestExtractNoArticle(t *testing.T) {
+	page := `<html><body><nav><a href="/">Home</a></nav><p>Too short.</p></body></html>`
+	_, err := readability.Extract(strings.NewReader(page), nil)
+	if !errors.Is(err, readability.ErrNoArticle) {
+		t.Errorf("Expected ErrNoArticle, got %v", err)
+	}
+}
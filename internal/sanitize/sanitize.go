@@ -0,0 +1,159 @@
+// Package sanitize cleans the HTML that feeds and web pages publish: it
+// reduces markup to an allowlist for storage and renders it as plain text
+// for the terminal
+package sanitize
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedTags maps the elements kept by HTML to the attributes they keep
+var allowedTags = map[atom.Atom][]string{
+	atom.A: {"href", "title"}, atom.Abbr: {"title"}, atom.B: nil, atom.Blockquote: nil,
+	atom.Br: nil, atom.Caption: nil, atom.Cite: nil, atom.Code: nil,
+	atom.Dd: nil, atom.Del: nil, atom.Div: nil, atom.Dl: nil,
+	atom.Dt: nil, atom.Em: nil, atom.Figcaption: nil, atom.Figure: nil,
+	atom.H1: nil, atom.H2: nil, atom.H3: nil, atom.H4: nil,
+	atom.H5: nil, atom.H6: nil, atom.Hr: nil, atom.I: nil,
+	atom.Img: {"src", "alt", "title", "width", "height"}, atom.Ins: nil, atom.Kbd: nil, atom.Li: nil,
+	atom.Mark: nil, atom.Ol: {"start"}, atom.P: nil, atom.Pre: nil,
+	atom.Q: nil, atom.S: nil, atom.Samp: nil, atom.Small: nil,
+	atom.Span: nil, atom.Strong: nil, atom.Sub: nil, atom.Sup: nil,
+	atom.Table: nil, atom.Tbody: nil, atom.Td: {"colspan", "rowspan"}, atom.Tfoot: nil,
+	atom.Th: {"colspan", "rowspan"}, atom.Thead: nil, atom.Tr: nil, atom.U: nil,
+	atom.Ul: nil,
+}
+
+// droppedTags are removed along with everything inside them; other
+// elements outside the allowlist are replaced by their contents
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Frame: true, atom.Frameset: true, atom.Object: true,
+	atom.Embed: true, atom.Applet: true, atom.Form: true, atom.Button: true,
+	atom.Input: true, atom.Select: true, atom.Textarea: true, atom.Svg: true,
+	atom.Math: true, atom.Head: true, atom.Title: true, atom.Meta: true,
+	atom.Link: true, atom.Base: true,
+}
+
+// voidTags have no closing tag
+var voidTags = map[atom.Atom]bool{atom.Br: true, atom.Hr: true, atom.Img: true}
+
+// HTML reduces an HTML fragment to an allowlist of formatting elements and
+// attributes. Scripts, styles, embeds and forms are removed with their
+// contents, other elements are replaced by their contents, and links and
+// images may only point to http, https or relative URLs (and links also to
+// mailto:)
+func HTML(fragment string) string {
+	nodes, err := parseFragment(fragment)
+	if err != nil {
+		return html.EscapeString(fragment)
+	}
+
+	var buf strings.Builder
+	for _, node := range nodes {
+		writeHTML(&buf, node)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// writeHTML writes the allowed parts of a node and its children
+func writeHTML(buf *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		writeChildren(buf, n)
+		return
+	}
+
+	if droppedTags[n.DataAtom] {
+		return
+	}
+	allowed, ok := allowedTags[n.DataAtom]
+	if !ok {
+		writeChildren(buf, n)
+		return
+	}
+
+	buf.WriteByte('<')
+	buf.WriteString(n.Data)
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !slices.Contains(allowed, a.Key) {
+			continue
+		}
+		value := a.Val
+		if a.Key == "href" || a.Key == "src" {
+			var ok bool
+			if value, ok = safeURL(value, a.Key == "href"); !ok {
+				continue
+			}
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(a.Key)
+		buf.WriteString(`="`)
+		buf.WriteString(html.EscapeString(value))
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+
+	if voidTags[n.DataAtom] {
+		return
+	}
+	writeChildren(buf, n)
+	buf.WriteString("</")
+	buf.WriteString(n.Data)
+	buf.WriteByte('>')
+}
+
+// writeChildren writes the allowed parts of each child of a node
+func writeChildren(buf *strings.Builder, n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeHTML(buf, child)
+	}
+}
+
+// safeURL reports whether a link or image URL can be kept, returning it
+// trimmed. Relative URLs are allowed, absolute ones only for http and
+// https, and for links also mailto
+func safeURL(value string, link bool) (string, bool) {
+	value = strings.TrimSpace(value)
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return "", false
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https":
+		return value, true
+	case "mailto":
+		return value, link
+	default:
+		return "", false
+	}
+}
+
+// parseFragment parses HTML as the contents of a <body> element
+func parseFragment(fragment string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(fragment), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+}
+
+// attr returns the value of an element's attribute
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key && a.Namespace == "" {
+			return a.Val
+		}
+	}
+	return ""
+}
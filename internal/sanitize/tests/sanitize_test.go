@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/sanitize"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "keeps formatting",
+			html: `<p>Some <strong>bold</strong> and <em>italic</em> text</p>`,
+			want: `<p>Some <strong>bold</strong> and <em>italic</em> text</p>`,
+		},
+		{
+			name: "removes scripts and styles with their contents",
+			html: `<p>Hi</p><script>alert("x")</script><style>p { color: red }</style>`,
+			want: `<p>Hi</p>`,
+		},
+		{
+			name: "unwraps unknown elements",
+			html: `<section><font color="red">Old <blink>markup</blink></font></section>`,
+			want: `Old markup`,
+		},
+		{
+			name: "strips disallowed attributes",
+			html: `<p class="lead" style="color: red" onclick="steal()">Text</p>`,
+			want: `<p>Text</p>`,
+		},
+		{
+			name: "keeps safe links and images",
+			html: `<a href="https://example.org/?a=1&b=2" target="_blank">Link</a><img src="/logo.png" alt="Logo" onerror="x()">`,
+			want: `<a href="https://example.org/?a=1&amp;b=2">Link</a><img src="/logo.png" alt="Logo">`,
+		},
+		{
+			name: "drops unsafe URLs",
+			html: `<a href="javascript:alert(1)">Click</a><img src="data:image/png;base64,AAAA"><a href="mailto:me@example.org">Mail</a>`,
+			want: `<a>Click</a><img><a href="mailto:me@example.org">Mail</a>`,
+		},
+		{
+			name: "escapes text",
+			html: `Fish &amp; chips &lt;3`,
+			want: `Fish &amp; chips &lt;3`,
+		},
+		{
+			name: "closes unclosed tags",
+			html: `<p>Open <b>bold`,
+			want: `<p>Open <b>bold</b></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize.HTML(tt.html); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		width int
+		want  string
+	}{
+		{
+			name: "paragraphs",
+			html: "<p>First   paragraph\n with <em>emphasis</em>.</p><p>Second.</p>",
+			want: "First paragraph with emphasis.\n\nSecond.",
+		},
+		{
+			name: "line breaks",
+			html: "One<br>Two<br/>Three",
+			want: "One\nTwo\nThree",
+		},
+		{
+			name: "lists",
+			html: `<p>Steps:</p><ol start="3"><li>Fetch</li><li><p>Parse</p></li></ol><ul><li>Done</li></ul>`,
+			want: "Steps:\n\n3. Fetch\n4. Parse\n\n- Done",
+		},
+		{
+			name: "preformatted",
+			html: "<pre>if x {\n    y()\n}</pre>",
+			want: "if x {\n    y()\n}",
+		},
+		{
+			name: "images and scripts",
+			html: `<p>See <img src="a.png" alt="a chart"> here<script>alert(1)</script></p>`,
+			want: "See [a chart] here",
+		},
+		{
+			name: "link footnotes",
+			html: `<p>Read <a href="https://example.org/a">the docs</a>, <a href="https://example.org/b">the spec</a> and <a href="https://example.org/a">the docs again</a>. See https://example.org/c at <a href="https://example.org/c">example.org/c</a> or <a href="#top">the top</a>.</p>`,
+			want: "Read the docs[1], the spec[2] and the docs again[1]. See https://example.org/c at example.org/c or the top.\n\n[1] https://example.org/a\n[2] https://example.org/b",
+		},
+		{
+			name:  "wrapping",
+			html:  "<p>The quick brown fox jumps over the lazy dog</p>",
+			width: 16,
+			want:  "The quick brown\nfox jumps over\nthe lazy dog",
+		},
+		{
+			name:  "wrapped list items are indented",
+			html:  "<ul><li>one two three four five</li></ul>",
+			width: 12,
+			want:  "- one two\n  three four\n  five",
+		},
+		{
+			name:  "long words are not split",
+			html:  "<p>a supercalifragilistic word</p>",
+			width: 10,
+			want:  "a\nsupercalifragilistic\nword",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize.Text(tt.html, tt.width); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInline(t *testing.T) {
+	got := sanitize.Inline(`<p>Hello <a href="https://example.org">world</a>!</p><ul><li>One</li><li>Two</li></ul>`)
+	want := "Hello world! - One - Two"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Search snippets are fragments of HTML that may not close their tags
+	got = sanitize.Inline("<em>Go</em> **release**</a> notes ... <p>New\n<b>**release**")
+	want = "Go **release** notes ... New **release**"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		n    int
+		want string
+	}{
+		{name: "short text is unchanged", text: "Hello", n: 10, want: "Hello"},
+		{name: "cuts between words", text: "The quick brown fox jumps", n: 16, want: "The quick..."},
+		{name: "cuts at a word boundary", text: "The quick brown fox", n: 12, want: "The quick..."},
+		{name: "cuts a long word", text: "Supercalifragilistic", n: 10, want: "Superca..."},
+		{name: "counts characters, not bytes", text: "héllo wörld ünïcode", n: 14, want: "héllo wörld..."},
+		{name: "tiny limits", text: "Hello", n: 2, want: ".."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize.Truncate(tt.text, tt.n); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
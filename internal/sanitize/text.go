@@ -0,0 +1,334 @@
+package sanitize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// paragraphTags are elements rendered as paragraphs separated by a blank line
+var paragraphTags = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Figcaption: true, atom.Figure: true, atom.Footer: true, atom.H1: true,
+	atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true,
+	atom.H6: true, atom.Header: true, atom.Hr: true, atom.Main: true,
+	atom.P: true, atom.Pre: true, atom.Section: true, atom.Table: true,
+}
+
+// Text renders an HTML fragment as plain text for the terminal: blocks
+// become paragraphs separated by blank lines, list items are marked with
+// "-" or their number, and lines are wrapped at width characters (or not
+// at all when width is 0). Links are numbered like "text[1]" and their
+// URLs listed as footnotes at the end
+func Text(fragment string, width int) string {
+	w := textWriter{width: width, footnotes: true}
+	w.render(fragment)
+
+	text := w.String()
+	if len(w.links) == 0 {
+		return text
+	}
+
+	var footnotes strings.Builder
+	for i, link := range w.links {
+		fmt.Fprintf(&footnotes, "\n[%d] %s", i+1, link)
+	}
+	return text + "\n" + footnotes.String()
+}
+
+// Inline renders an HTML fragment as a single line of plain text, for
+// table cells and other places without room for paragraphs
+func Inline(fragment string) string {
+	var w textWriter
+	w.render(fragment)
+	return strings.Join(strings.Fields(w.String()), " ")
+}
+
+// Truncate shortens text to at most n characters, ending it with "..."
+// when anything was cut. It cuts between words when one ends in the
+// second half of the allowed length, and never splits a character
+func Truncate(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	if n <= 3 {
+		return strings.Repeat(".", max(n, 0))
+	}
+
+	runes := []rune(text)
+	cut := n - 3
+	// Back up to the last space when the cut falls inside a word
+	if !unicode.IsSpace(runes[cut]) {
+		for i := cut - 1; i > cut/2; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "..."
+}
+
+// textWriter collects text, collapsing whitespace, wrapping lines, and
+// owing line breaks and spaces until the next text arrives so none pile up
+type textWriter struct {
+	buf    strings.Builder
+	width  int  // column to wrap at, or 0 not to wrap
+	col    int  // characters on the current line
+	indent int  // columns wrapped lines in a list item are indented by
+	breaks int  // newlines owed before the next text
+	space  bool // a space is owed before the next text
+	marker bool // a list marker was just written
+
+	footnotes bool           // number links and collect their URLs
+	links     []string       // footnoted URLs in order
+	linkIndex map[string]int // footnote number of each URL
+}
+
+// render walks a parsed fragment
+func (w *textWriter) render(fragment string) {
+	nodes, err := parseFragment(fragment)
+	if err != nil {
+		w.text(fragment)
+		return
+	}
+	for _, node := range nodes {
+		w.walk(node, false)
+	}
+}
+
+// String returns the text with trailing spaces removed from every line
+func (w *textWriter) String() string {
+	lines := strings.Split(w.buf.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// lineBreak owes n newlines before the next text. Nothing is owed at the
+// start of the text or straight after a list marker
+func (w *textWriter) lineBreak(n int) {
+	if w.buf.Len() == 0 || w.marker {
+		return
+	}
+	w.breaks = max(w.breaks, n)
+	w.space = false
+}
+
+// raw writes s and keeps track of the column
+func (w *textWriter) raw(s string) {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		w.col = utf8.RuneCountInString(s[i+1:])
+	} else {
+		w.col += utf8.RuneCountInString(s)
+	}
+	w.buf.WriteString(s)
+}
+
+// newline ends the line and indents the next one
+func (w *textWriter) newline(n int) {
+	w.raw(strings.Repeat("\n", n) + strings.Repeat(" ", w.indent))
+}
+
+// write adds s after any line breaks or space owed, wrapping first when
+// s would run past the width
+func (w *textWriter) write(s string) {
+	if s == "" {
+		return
+	}
+
+	length := utf8.RuneCountInString(s)
+	switch {
+	case w.breaks > 0:
+		w.newline(w.breaks)
+	case w.space && w.buf.Len() > 0:
+		if w.width > 0 && w.col+1+length > w.width && w.col > w.indent {
+			w.newline(1)
+		} else {
+			w.raw(" ")
+		}
+	}
+	w.breaks, w.space, w.marker = 0, false, false
+	w.raw(s)
+}
+
+// text adds text word by word with runs of whitespace collapsed
+func (w *textWriter) text(s string) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			w.space = true
+		}
+		return
+	}
+
+	if first, _ := utf8.DecodeRuneInString(s); unicode.IsSpace(first) {
+		w.space = true
+	}
+	for i, field := range fields {
+		if i > 0 {
+			w.space = true
+		}
+		w.write(field)
+	}
+	if last, _ := utf8.DecodeLastRuneInString(s); unicode.IsSpace(last) {
+		w.space = true
+	}
+}
+
+// walk renders a node and its children; pre keeps whitespace as it is
+func (w *textWriter) walk(n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if pre {
+			w.write(n.Data)
+		} else {
+			w.text(n.Data)
+		}
+		return
+	case html.ElementNode:
+	default:
+		w.children(n, pre)
+		return
+	}
+
+	if droppedTags[n.DataAtom] {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		w.lineBreak(1)
+		return
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			w.text(" [" + alt + "] ")
+		}
+		return
+	case atom.A:
+		w.children(n, pre)
+		w.footnote(n)
+		return
+	case atom.Ul, atom.Ol:
+		w.list(n, pre)
+		return
+	case atom.Tr:
+		w.lineBreak(1)
+		w.children(n, pre)
+		w.lineBreak(1)
+		return
+	case atom.Td, atom.Th:
+		w.space = true
+		w.children(n, pre)
+		w.space = true
+		return
+	}
+
+	block := paragraphTags[n.DataAtom]
+	if block {
+		w.lineBreak(2)
+	}
+	w.children(n, pre || n.DataAtom == atom.Pre)
+	if block {
+		w.lineBreak(2)
+	}
+}
+
+// children renders each child of a node in turn
+func (w *textWriter) children(n *html.Node, pre bool) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.walk(child, pre)
+	}
+}
+
+// footnote numbers a link after its text, unless the text already shows
+// the URL or the link points within the page
+func (w *textWriter) footnote(n *html.Node) {
+	href, ok := safeURL(attr(n, "href"), true)
+	if !w.footnotes || !ok || href == "" || strings.HasPrefix(href, "#") {
+		return
+	}
+	if text := strings.TrimSpace(innerText(n)); text == href || text == strings.TrimPrefix(strings.TrimPrefix(href, "https://"), "http://") {
+		return
+	}
+
+	index, seen := w.linkIndex[href]
+	if !seen {
+		if w.linkIndex == nil {
+			w.linkIndex = make(map[string]int)
+		}
+		w.links = append(w.links, href)
+		index = len(w.links)
+		w.linkIndex[href] = index
+	}
+
+	// Attach the number to the link text even if a space is owed after it
+	space := w.space
+	w.space = false
+	w.write("[" + strconv.Itoa(index) + "]")
+	w.space = space
+}
+
+// list renders a list's items on their own lines, numbered for <ol> and
+// marked with "-" otherwise, with wrapped lines indented under the text
+func (w *textWriter) list(n *html.Node, pre bool) {
+	if w.indent > 0 {
+		w.lineBreak(1)
+	} else {
+		w.lineBreak(2)
+	}
+
+	index := 0
+	if n.DataAtom == atom.Ol {
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			index = start - 1
+		}
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			w.walk(child, pre)
+			continue
+		}
+
+		index++
+		marker := "-"
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(index) + "."
+		}
+		w.lineBreak(1)
+		w.write(marker)
+		w.space, w.marker = true, true
+
+		indent := w.indent
+		w.indent += utf8.RuneCountInString(marker) + 1
+		w.children(child, pre)
+		w.indent = indent
+		w.marker = false
+	}
+
+	if w.indent > 0 {
+		w.lineBreak(1)
+	} else {
+		w.lineBreak(2)
+	}
+}
+
+// innerText returns the text inside a node
+func innerText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var buf strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		buf.WriteString(innerText(child))
+	}
+	return buf.String()
+}
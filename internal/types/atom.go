@@ -0,0 +1,78 @@
+package types
+
+// Atom 1.0 types, normalized into RSSFeed by the feeds package
+
+// AtomFeed represents an Atom 1.0 <feed> document
+type AtomFeed struct {
+	Title    AtomText     `xml:"title"`
+	Subtitle AtomText     `xml:"subtitle"`
+	Links    []AtomLink   `xml:"link"`
+	Authors  []AtomPerson `xml:"author"`
+	Entries  []AtomEntry  `xml:"entry"`
+}
+
+// AtomEntry represents a single <entry> in an Atom feed
+type AtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      AtomText       `xml:"title"`
+	Links      []AtomLink     `xml:"link"`
+	Summary    AtomText       `xml:"summary"`
+	Content    AtomText       `xml:"content"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Authors    []AtomPerson   `xml:"author"`
+	Categories []AtomCategory `xml:"category"`
+}
+
+// AtomLink represents an Atom <link> element
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// AtomPerson represents an Atom <author> or <contributor> element
+type AtomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+}
+
+// AtomCategory represents an Atom <category> element, whose label is
+// optional and defaults to its term
+type AtomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+// AtomText represents an Atom text construct, which may hold plain text,
+// escaped HTML or inline XHTML depending on its type attribute
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// String returns the text construct's content, keeping inline XHTML markup
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+	return t.Text
+}
+
+// AlternateLink returns the href of the rel="alternate" link, falling back
+// to the first link without a rel attribute
+func AlternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	for _, link := range links {
+		if link.Rel == "" {
+			return link.Href
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package types
+
+// JSON Feed 1.1 types, normalized into RSSFeed by the feeds package
+
+// JSONFeed represents a JSON Feed 1.1 document
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+// JSONFeedItem represents a single item in a JSON Feed
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+	Image         string `json:"image"`
+	// Author is the single author of JSON Feed 1.0, replaced by Authors in 1.1
+	Author      *JSONFeedAuthor      `json:"author"`
+	Authors     []JSONFeedAuthor     `json:"authors"`
+	Tags        []string             `json:"tags"`
+	Attachments []JSONFeedAttachment `json:"attachments"`
+}
+
+// JSONFeedAuthor represents an item's author
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// JSONFeedAttachment represents a file attached to an item, like a podcast episode
+type JSONFeedAttachment struct {
+	URL               string `json:"url"`
+	MimeType          string `json:"mime_type"`
+	SizeInBytes       int64  `json:"size_in_bytes"`
+	DurationInSeconds int64  `json:"duration_in_seconds"`
+}
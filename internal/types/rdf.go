@@ -0,0 +1,15 @@
+package types
+
+// RSS 1.0 (RDF) types, normalized into RSSFeed by the feeds package
+
+// RDFFeed represents an RSS 1.0 <rdf:RDF> document, where items are
+// siblings of the channel rather than children of it
+type RDFFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+		ScheduleHints
+	} `xml:"channel"`
+	Item []RSSItem `xml:"item"`
+}
@@ -5,17 +5,52 @@ package types
 // RSSFeed represents an RSS feed with its channels and items
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title       string      `xml:"title"`
+		Link        string      `xml:"link"`
+		Description string      `xml:"description"`
+		Item        []RSSItem   `xml:"item"`
+		ITunesImage ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		ScheduleHints
 	} `xml:"channel"`
 }
 
+// ScheduleHints holds the publisher's polling hints from a channel:
+// <ttl>, the syndication module's update period and <skipHours>
+type ScheduleHints struct {
+	TTL             string   `xml:"ttl"`
+	UpdatePeriod    string   `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+	UpdateFrequency string   `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	SkipHours       []string `xml:"skipHours>hour"`
+}
+
 // RSSItem represents a single item in an RSS feed
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	GUID        string         `xml:"guid"`
+	Description string         `xml:"description"`
+	Content     string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string         `xml:"pubDate"`
+	DCDate      string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Authors     []string       `xml:"author"`
+	DCCreators  []string       `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string       `xml:"category"`
+	Enclosures  []RSSEnclosure `xml:"enclosure"`
+	// Podcast episode details from the iTunes namespace
+	ITunesDuration string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ITunesEpisode  string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	ITunesImage    ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+}
+
+// ITunesImage represents an itunes:image element, which holds its URL in
+// an href attribute
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// RSSEnclosure represents a media file attached to an item
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
 }
\ No newline at end of file
@@ -0,0 +1,86 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// apiKeyPrefix marks rssagg API keys so they are easy to recognize
+const apiKeyPrefix = "rssagg_"
+
+// tokenBytes is the amount of randomness in API keys and session tokens
+const tokenBytes = 32
+
+// ErrInvalidAPIKey is returned when an API key doesn't belong to any user
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// randomToken returns tokenBytes of randomness, hex encoded
+func randomToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateAPIKey returns a new random API key
+func GenerateAPIKey() (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return apiKeyPrefix + token, nil
+}
+
+// HashToken returns the hash stored for an API key or session token. Tokens
+// are long and random, so a fast unsalted hash is enough and lets them be
+// looked up directly
+func HashToken(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// RotateAPIKey replaces a user's API key, returning the new key. The old key
+// stops working immediately
+func (s *Service) RotateAPIKey(ctx context.Context, userID uuid.UUID) (string, error) {
+	key, err := GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	params := database.SetUserAPIKeyHashParams{
+		ID:         userID,
+		ApiKeyHash: pgtype.Text{String: HashToken(key), Valid: true},
+	}
+	if err := s.DB.SetUserAPIKeyHash(ctx, params); err != nil {
+		return "", fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// AuthenticateAPIKey returns the user an API key belongs to
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (database.User, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return database.User{}, ErrInvalidAPIKey
+	}
+
+	user, err := s.DB.GetUserByAPIKeyHash(ctx, pgtype.Text{String: HashToken(key), Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.User{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	return user, nil
+}
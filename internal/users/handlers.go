@@ -6,9 +6,11 @@ import (
 	"fmt"
 
 	"github.com/abahnj/rssagg/internal/cli"
+	"github.com/abahnj/rssagg/internal/database"
 )
 
-// HandlerLogin handles the login command
+// HandlerLogin handles the login command, prompting for the user's
+// password if they registered with one
 func HandlerLogin(s *cli.State, cmd cli.Command) error {
 	if len(cmd.Args) < 1 {
 		return ErrMissingUsername
@@ -23,20 +25,31 @@ func HandlerLogin(s *cli.State, cmd cli.Command) error {
 	ctx := context.Background()
 
 	service := NewService(*s.Db)
-	_, err := service.Login(ctx, username)
+	user, err := service.Login(ctx, username)
 	if err != nil {
 		return err
 	}
 
-	if err := s.Config.SetUser(username); err != nil {
-		return fmt.Errorf("failed to set user: %w", err)
+	if user.PasswordHash.Valid {
+		password, err := ReadPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		if err := CheckPassword(user, password); err != nil {
+			return err
+		}
+	}
+
+	if err := startSession(ctx, s, service, user); err != nil {
+		return err
 	}
 
 	fmt.Printf("User %s logged in\n", username)
 	return nil
 }
 
-// HandlerRegister handles the register command
+// HandlerRegister handles the register command; "register <name> --password"
+// prompts for a password the user will need to log in
 func HandlerRegister(s *cli.State, cmd cli.Command) error {
 	if len(cmd.Args) < 1 {
 		return errors.New("username is required for register")
@@ -50,13 +63,74 @@ func HandlerRegister(s *cli.State, cmd cli.Command) error {
 	username := cmd.Args[0]
 	ctx := context.Background()
 	
+	var password string
+	if len(cmd.Args) > 1 {
+		if cmd.Args[1] != "--password" {
+			return fmt.Errorf("unknown flag: %s", cmd.Args[1])
+		}
+		
+		var err error
+		password, err = ReadPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		confirmation, err := ReadPassword("Confirm password: ")
+		if err != nil {
+			return err
+		}
+		if password != confirmation {
+			return errors.New("passwords do not match")
+		}
+	}
+	
 	service := NewService(*s.Db)
-	_, err := service.Register(ctx, username)
+	user, apiKey, err := service.Register(ctx, username, password)
 	if err != nil {
 		return err
 	}
+	
+	if err := startSession(ctx, s, service, user); err != nil {
+		return err
+	}
+	
+	fmt.Printf("User %s registered and logged in\n", username)
+	fmt.Printf("API key: %s\n", apiKey)
+	fmt.Println("Store it somewhere safe, it won't be shown again (rotate it with: apikey rotate)")
+	return nil
+}
+
+// HandlerLogout handles the logout command to end the current session
+func HandlerLogout(s *cli.State, cmd cli.Command) error {
+	if s.Config == nil || s.Config.SessionToken == "" {
+		return errors.New("you are not logged in")
+	}
+	
+	ctx := context.Background()
+	
+	service := NewService(*s.Db)
+	if err := service.EndSession(ctx, s.Config.SessionToken); err != nil {
+		return err
+	}
+	
+	if err := s.Config.SetSession("", ""); err != nil {
+		return fmt.Errorf("failed to clear session: %w", err)
+	}
+	
+	fmt.Println("Logged out")
+	return nil
+}
 
-	return HandlerLogin(s, cmd)
+// startSession creates a session for the user and caches its token in the config
+func startSession(ctx context.Context, s *cli.State, service *Service, user database.User) error {
+	token, err := service.CreateSession(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	
+	if err := s.Config.SetSession(user.Name, token); err != nil {
+		return fmt.Errorf("failed to set user: %w", err)
+	}
+	return nil
 }
 
 // HandlerDeleteAllUsers handles the reset command
@@ -87,13 +161,31 @@ func HandlerListUsers(s *cli.State, cmd cli.Command) error {
 		currentUserName = s.Config.CurrentUserName
 	}
 	
+	table := cli.NewTable("name", "current", "created_at")
+	table.Empty = "No users found"
 	for _, user := range users {
-		if user.Name == currentUserName {
-			fmt.Printf("* %s (current)\n", user.Name)
-		} else {
-			fmt.Printf("* %s\n", user.Name)
-		}
+		table.AddRow(user.Name, user.Name == currentUserName, user.CreatedAt.Time)
+	}
+	
+	return s.Render(table)
+}
+
+// HandlerAPIKey handles the apikey command; "apikey rotate" replaces the
+// user's API key and shows the new one
+func HandlerAPIKey(s *cli.State, cmd cli.Command, user database.User) error {
+	if len(cmd.Args) < 1 || cmd.Args[0] != "rotate" {
+		return errors.New("usage: apikey rotate")
+	}
+	
+	ctx := context.Background()
+	
+	service := NewService(*s.Db)
+	apiKey, err := service.RotateAPIKey(ctx, user.ID)
+	if err != nil {
+		return err
 	}
 	
+	fmt.Printf("New API key for %s: %s\n", user.Name, apiKey)
+	fmt.Println("The previous key no longer works")
 	return nil
 }
\ No newline at end of file
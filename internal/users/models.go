@@ -7,6 +7,7 @@ import (
 
 	"github.com/abahnj/rssagg/internal/database"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 // ErrMissingUsername is returned when the login command doesn't have a username argument
@@ -33,19 +34,37 @@ func (s *Service) Login(ctx context.Context, username string) (database.User, er
 	return user, nil
 }
 
-// Register creates a new user
-func (s *Service) Register(ctx context.Context, username string) (database.User, error) {
+// Register creates a new user with an API key, returning the key. Only its
+// hash is stored, so this is the only time the key is available. An empty
+// password creates a user who can log in without one
+func (s *Service) Register(ctx context.Context, username, password string) (database.User, string, error) {
+	var passwordHash pgtype.Text
+	if password != "" {
+		hash, err := HashPassword(password)
+		if err != nil {
+			return database.User{}, "", err
+		}
+		passwordHash = pgtype.Text{String: hash, Valid: true}
+	}
+
+	key, err := GenerateAPIKey()
+	if err != nil {
+		return database.User{}, "", err
+	}
+
 	createUserParams := database.CreateUserParams{
-		ID:   uuid.New(),
-		Name: username,
+		ID:           uuid.New(),
+		Name:         username,
+		ApiKeyHash:   pgtype.Text{String: HashToken(key), Valid: true},
+		PasswordHash: passwordHash,
 	}
 
 	user, err := s.DB.CreateUser(ctx, createUserParams)
 	if err != nil {
-		return database.User{}, fmt.Errorf("failed to create user: %w", err)
+		return database.User{}, "", fmt.Errorf("failed to create user: %w", err)
 	}
 
-	return user, nil
+	return user, key, nil
 }
 
 // GetUsers retrieves all users
@@ -0,0 +1,96 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+)
+
+// MinPasswordLength is the shortest password register accepts
+const MinPasswordLength = 8
+
+var (
+	// ErrPasswordTooShort is returned when a new password is shorter than MinPasswordLength
+	ErrPasswordTooShort = fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	// ErrPasswordTooLong is returned when a new password exceeds bcrypt's 72-byte limit
+	ErrPasswordTooLong = errors.New("password must be at most 72 bytes")
+	// ErrWrongPassword is returned when a password doesn't match the user's
+	ErrWrongPassword = errors.New("incorrect password")
+)
+
+// HashPassword validates a new password and returns its bcrypt hash
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+	if len(password) > 72 {
+		return "", ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword verifies a password against a user's stored hash. Users
+// registered without a password accept any password
+func CheckPassword(user database.User, password string) error {
+	if !user.PasswordHash.Valid {
+		return nil
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrWrongPassword
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check password: %w", err)
+	}
+	return nil
+}
+
+// ReadPassword prompts for a password on stderr and reads it from stdin,
+// without echoing it when stdin is a terminal
+func ReadPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return string(password), nil
+	}
+
+	// Allow piping the password in, e.g. from a secret manager. Read a byte
+	// at a time so nothing past this line is consumed and a confirmation
+	// prompt can read the next one
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(line) > 0 {
+				break
+			}
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+	}
+	return strings.TrimRight(string(line), "\r"), nil
+}
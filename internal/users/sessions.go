@@ -0,0 +1,68 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SessionLifetime is how long a login lasts before the user must log in again
+const SessionLifetime = 30 * 24 * time.Hour
+
+// ErrInvalidSession is returned when a session token is unknown or expired
+var ErrInvalidSession = errors.New("session expired or invalid, please log in again")
+
+// CreateSession starts a session for a user, returning the token to cache
+// locally. Only the token's hash is stored
+func (s *Service) CreateSession(ctx context.Context, userID uuid.UUID) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	// Clean up old sessions while we're here
+	if err := s.DB.DeleteExpiredSessions(ctx); err != nil {
+		return "", fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	params := database.CreateSessionParams{
+		TokenHash: HashToken(token),
+		UserID:    userID,
+		Lifetime:  pgtype.Interval{Microseconds: SessionLifetime.Microseconds(), Valid: true},
+	}
+	if err := s.DB.CreateSession(ctx, params); err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return token, nil
+}
+
+// UserForSession returns the user a live session token belongs to
+func (s *Service) UserForSession(ctx context.Context, token string) (database.User, error) {
+	if token == "" {
+		return database.User{}, ErrInvalidSession
+	}
+
+	user, err := s.DB.GetUserBySessionTokenHash(ctx, HashToken(token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.User{}, ErrInvalidSession
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to look up session: %w", err)
+	}
+	return user, nil
+}
+
+// EndSession deletes a session so its token stops working
+func (s *Service) EndSession(ctx context.Context, token string) error {
+	if err := s.DB.DeleteSession(ctx, HashToken(token)); err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	return nil
+}
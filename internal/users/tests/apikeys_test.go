@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/users"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	first, err := users.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := users.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(first, "rssagg_") || len(first) != len("rssagg_")+64 {
+		t.Errorf("Unexpected key format: %s", first)
+	}
+	if first == second {
+		t.Error("Expected generated keys to differ")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	key := "rssagg_0123456789abcdef"
+	hash := users.HashToken(key)
+
+	if hash != users.HashToken(key) {
+		t.Error("Expected hashing to be deterministic")
+	}
+	if hash == key || strings.Contains(hash, "0123456789abcdef") {
+		t.Errorf("Expected hash not to contain the key, got %s", hash)
+	}
+	if hash == users.HashToken(key+"0") {
+		t.Error("Expected different keys to have different hashes")
+	}
+}
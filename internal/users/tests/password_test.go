@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/abahnj/rssagg/internal/database"
+	"github.com/abahnj/rssagg/internal/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestHashPassword(t *testing.T) {
+	t.Run("Too short", func(t *testing.T) {
+		if _, err := users.HashPassword("short"); !errors.Is(err, users.ErrPasswordTooShort) {
+			t.Errorf("Expected ErrPasswordTooShort, got %v", err)
+		}
+	})
+
+	t.Run("Too long", func(t *testing.T) {
+		if _, err := users.HashPassword(strings.Repeat("a", 73)); !errors.Is(err, users.ErrPasswordTooLong) {
+			t.Errorf("Expected ErrPasswordTooLong, got %v", err)
+		}
+	})
+
+	t.Run("Hash is salted", func(t *testing.T) {
+		first, err := users.HashPassword("correct horse")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		second, err := users.HashPassword("correct horse")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if first == second {
+			t.Error("Expected hashes of the same password to differ")
+		}
+		if strings.Contains(first, "correct horse") {
+			t.Error("Expected hash not to contain the password")
+		}
+	})
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := users.HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	user := database.User{Name: "alice", PasswordHash: pgtype.Text{String: hash, Valid: true}}
+
+	if err := users.CheckPassword(user, "correct horse"); err != nil {
+		t.Errorf("Expected correct password to be accepted, got %v", err)
+	}
+	if err := users.CheckPassword(user, "battery staple"); !errors.Is(err, users.ErrWrongPassword) {
+		t.Errorf("Expected ErrWrongPassword, got %v", err)
+	}
+
+	// Users registered without a password don't need one
+	if err := users.CheckPassword(database.User{Name: "bob"}, ""); err != nil {
+		t.Errorf("Expected passwordless user to be accepted, got %v", err)
+	}
+}
+
+func TestReadPasswordPiped(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+
+	originalStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = originalStdin }()
+
+	// Both lines arrive in one write, as when piping into register --password
+	if _, err := w.WriteString("correct horse\r\ncorrect horse\nlast"); err != nil {
+		t.Fatalf("Failed to write to pipe: %v", err)
+	}
+	w.Close()
+
+	for _, want := range []string{"correct horse", "correct horse", "last"} {
+		got, err := users.ReadPassword("")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+
+	if _, err := users.ReadPassword(""); err == nil {
+		t.Error("Expected error once input is exhausted")
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/abahnj/rssagg/internal/cli"
 	"github.com/abahnj/rssagg/internal/config"
 	"github.com/abahnj/rssagg/internal/database"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
@@ -19,17 +19,18 @@ func main() {
 		log.Fatalf("Failed to read config: %v", err)
 	}
 
-	conn, err := pgx.Connect(context.Background(), cfg.DBURL)
+	// Use a connection pool so concurrent scrapes don't share a connection
+	pool, err := pgxpool.New(context.Background(), cfg.DBURL)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to connect to database: %v\n", err)
 		os.Exit(1)
 	}
-	defer conn.Close(context.Background())
+	defer pool.Close()
 
 	// Create application state
 	state := &cli.State{
 		Config: &cfg,
-		Db:     database.New(conn),
+		Db:     database.New(pool),
 	}
 
 	// Set up commands
@@ -40,17 +41,39 @@ func main() {
 	args := os.Args
 	if len(args) < 2 {
 		fmt.Println("Available commands:")
-		fmt.Println("  login <username>  - Log in as a user")
-		fmt.Println("  register <username> - Register a new user")
+		fmt.Println("  login <username>  - Log in as a user (prompts for a password if they have one)")
+		fmt.Println("  logout - End your session")
+		fmt.Println("  register <username> [--password] - Register a new user, optionally protected by a password")
 		fmt.Println("  users - List all users")
+		fmt.Println("  apikey rotate - Replace your API key for the REST API")
 		fmt.Println("  reset - Delete all users")
 		fmt.Println("  feeds - List all feeds")
-		fmt.Println("  addfeed <name> <url> - Add a new feed")
+		fmt.Println("  addfeed <name> <url> [interval] - Add a new feed, optionally refreshed every [interval] (e.g. 15m)")
 		fmt.Println("  follow <url> - Follow an existing feed")
 		fmt.Println("  unfollow <url> - Unfollow a feed")
 		fmt.Println("  following - List feeds you're following")
-		fmt.Println("  browse [limit] - View posts from feeds you follow (default limit: 10)")
-		fmt.Println("  agg <duration> - Aggregate and show feed content every <duration> (e.g. 30s, 1m)")
+		fmt.Println("  fulltext <url> on|off - Fetch the full article behind each new post in a feed")
+		fmt.Println("  import <file.opml> - Follow every feed in an OPML file")
+		fmt.Println("  export [file] - Export the feeds you follow as OPML (default: standard output)")
+		fmt.Println("  feedhealth [enable <url>] - List failing and disabled feeds, or re-enable a feed")
+		fmt.Println("  browse [limit] [--unread] [--feed <url|name>] [--since <date|age>] [--until <date|age>] [--sort newest|oldest] [--page N] [--before|--after <cursor>] - View posts from feeds you follow (default limit: 10)")
+		fmt.Println("  read <post-id> - Show a post as plain text, using its full article when fetched, and mark it as read")
+		fmt.Println("  unread <post-id> - Mark a post as unread")
+		fmt.Println("  markall [feed-url] - Mark all posts, or all posts in one feed, as read")
+		fmt.Println("  star <post-id> - Save a post to your starred posts")
+		fmt.Println("  unstar <post-id> - Remove a post from your starred posts")
+		fmt.Println("  starred [limit] - View your starred posts (default limit: 10)")
+		fmt.Println("  filter add [--feed <url>] [--title-regex <regex>] [--keyword <word>] --action hide|highlight|star - Add a filter rule")
+		fmt.Println("  filter list - List your filter rules")
+		fmt.Println("  filter rm <rule-id> - Remove a filter rule")
+		fmt.Println("  search <query> [--all] - Search posts in feeds you follow, or in all feeds with --all")
+		fmt.Println("  outfeed [--format rss|atom] [--limit N] - Write your latest posts as one RSS or Atom feed (default limit: 20)")
+		fmt.Println("  podcasts [limit] - List the newest podcast episodes from feeds you follow (default limit: 10)")
+		fmt.Println("  download <post-id> [dir] - Download a podcast episode to [dir] (default: current directory), resuming an interrupted download")
+		fmt.Println("  agg <duration> [concurrency] [timeout] - Aggregate feed content every <duration> (e.g. 30s, 1m), scraping up to [concurrency] feeds in parallel (default: 1, per-feed timeout: 30s)")
+		fmt.Println("  serve <addr> - Serve the REST API on <addr> (e.g. :8080)")
+		fmt.Println()
+		fmt.Println("Listing commands (users, feeds, following, feedhealth, browse, starred, search, podcasts) accept --output json|csv|tsv|table (default: table)")
 		os.Exit(0)
 	}
 
@@ -0,0 +1,9 @@
+-- name: CreateFeedError :exec
+INSERT INTO feed_errors (id, feed_id, error)
+VALUES ($1, $2, $3);
+
+-- name: GetFeedErrors :many
+SELECT * FROM feed_errors
+WHERE feed_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
\ No newline at end of file
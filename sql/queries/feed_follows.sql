@@ -34,4 +34,12 @@ FROM feed_follows ff
 JOIN users u ON ff.user_id = u.id
 JOIN feeds f ON ff.feed_id = f.id
 WHERE ff.user_id = $1
-ORDER BY ff.created_at DESC;
\ No newline at end of file
+ORDER BY ff.created_at DESC;
+
+-- name: MoveFeedFollows :exec
+UPDATE feed_follows
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id)
+  AND user_id NOT IN (
+    SELECT user_id FROM feed_follows WHERE feed_id = sqlc.arg(to_feed_id)
+  );
\ No newline at end of file
@@ -27,12 +27,73 @@ FROM feeds f
 JOIN users u ON f.user_id = u.id
 ORDER BY f.created_at DESC;
 
--- name: GetNextFeedToFetch :one
+-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), last_success_at = NOW(), consecutive_failures = 0, last_error = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFeedCacheValidators :exec
+UPDATE feeds
+SET etag = $2, last_modified = $3
+WHERE id = $1;
+
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET last_fetched_at = NOW(), next_fetch_at = NOW() + fetch_interval, updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE disabled_at IS NULL AND (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    ORDER BY next_fetch_at NULLS FIRST, last_fetched_at NULLS FIRST, updated_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET fetch_interval = sqlc.arg(fetch_interval), next_fetch_at = NOW() + sqlc.arg(delay)::interval,
+    publisher_min_interval = sqlc.arg(publisher_min_interval), skip_hours = sqlc.arg(skip_hours)
+WHERE id = sqlc.arg(id);
+
+-- name: SetFeedFetchIntervalOverride :exec
+UPDATE feeds
+SET fetch_interval_override = $2, fetch_interval = COALESCE($2, fetch_interval), next_fetch_at = NULL
+WHERE id = $1;
+
+-- name: SetFeedFetchFullArticle :execrows
+UPDATE feeds
+SET fetch_full_article = $2, updated_at = NOW()
+WHERE id = $1 AND EXISTS (
+    SELECT 1 FROM feed_follows ff WHERE ff.feed_id = feeds.id AND ff.user_id = $3
+);
+
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+    last_error = sqlc.arg(last_error),
+    next_fetch_at = NOW() + sqlc.arg(delay)::interval,
+    disabled_at = CASE
+        WHEN consecutive_failures + 1 >= sqlc.arg(max_failures)::integer THEN NOW()
+        ELSE disabled_at
+    END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetUnhealthyFeeds :many
 SELECT * FROM feeds
-ORDER BY last_fetched_at NULLS FIRST, updated_at
-LIMIT 1;
+WHERE consecutive_failures > 0 OR disabled_at IS NOT NULL
+ORDER BY disabled_at NULLS LAST, consecutive_failures DESC, name;
 
--- name: MarkFeedFetched :exec
+-- name: EnableFeed :exec
+UPDATE feeds
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL
+WHERE id = $1;
+
+-- name: UpdateFeedURL :one
 UPDATE feeds
-SET last_fetched_at = NOW(), updated_at = NOW()
-WHERE id = $1;
\ No newline at end of file
+SET url = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (id, user_id, feed_id, title_regex, keyword, action)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT r.id, r.user_id, r.feed_id, r.title_regex, r.keyword, r.action, r.created_at, f.url AS feed_url
+FROM filter_rules r
+LEFT JOIN feeds f ON r.feed_id = f.id
+WHERE r.user_id = $1
+ORDER BY r.created_at;
+
+-- name: GetFilterRulesForFeed :many
+SELECT r.id, r.user_id, r.feed_id, r.title_regex, r.keyword, r.action, r.created_at
+FROM filter_rules r
+JOIN feed_follows ff ON ff.user_id = r.user_id AND ff.feed_id = $1
+WHERE r.feed_id IS NULL OR r.feed_id = $1
+ORDER BY r.user_id, r.created_at;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: MoveFilterRules :exec
+UPDATE filter_rules
+SET feed_id = sqlc.arg(to_feed_id)::uuid
+WHERE feed_id = sqlc.arg(from_feed_id)::uuid;
\ No newline at end of file
@@ -0,0 +1,42 @@
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (post_id, url, type, length)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetEnclosuresForPosts :many
+SELECT * FROM post_enclosures
+WHERE post_id = ANY(sqlc.arg(post_ids)::uuid[])
+ORDER BY post_id, url;
+
+-- name: GetEpisodeEnclosure :one
+SELECT * FROM post_enclosures
+WHERE post_id = $1
+  AND (type IS NULL OR type LIKE 'audio/%' OR type LIKE 'video/%')
+ORDER BY url
+LIMIT 1;
+
+-- name: GetPodcastEpisodesForUser :many
+SELECT
+    p.id,
+    p.title,
+    f.name AS feed_name,
+    p.published_at,
+    p.episode,
+    p.duration_seconds,
+    p.image_url,
+    e.url AS enclosure_url,
+    e.type AS enclosure_type,
+    e.length AS enclosure_length
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+JOIN LATERAL (
+    SELECT pe.url, pe.type, pe.length FROM post_enclosures pe
+    WHERE pe.post_id = p.id
+      AND (pe.type IS NULL OR pe.type LIKE 'audio/%' OR pe.type LIKE 'video/%')
+    ORDER BY pe.url
+    LIMIT 1
+) e ON true
+WHERE ff.user_id = $1
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT $2;
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id)
+SELECT ff.user_id, p.id
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id)
+SELECT ff.user_id, p.id
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = $1 AND f.url = $2
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MoveDuplicatePostReads :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT pr.user_id, t.id, pr.read_at
+FROM post_reads pr
+JOIN posts p ON pr.post_id = p.id
+JOIN posts t ON t.feed_id = sqlc.arg(to_feed_id)
+  AND (p.canonical_url = t.canonical_url OR p.guid = t.guid)
+WHERE p.feed_id = sqlc.arg(from_feed_id)
+ON CONFLICT (user_id, post_id) DO NOTHING;
\ No newline at end of file
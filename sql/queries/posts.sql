@@ -1,6 +1,18 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO posts (
+    id, title, url, description, published_at, feed_id, guid, canonical_url, content, authors, categories,
+    duration_seconds, episode, image_url, duplicate_of
+)
+VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14,
+    (
+        SELECT o.id FROM posts o
+        WHERE o.canonical_url = $8 AND o.feed_id <> $6 AND o.duplicate_of IS NULL
+        ORDER BY o.created_at, o.id
+        LIMIT 1
+    )
+)
+ON CONFLICT DO NOTHING
 RETURNING *;
 
 -- name: GetPostsForUser :many
@@ -13,10 +25,124 @@ SELECT
     p.description,
     p.published_at,
     p.feed_id,
-    f.name AS feed_name
+    p.content,
+    p.authors,
+    p.categories,
+    f.name AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+    ) AS is_read,
+    COALESCE(p.published_at, p.created_at)::timestamp AS sort_at
 FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN feed_follows ff ON f.id = ff.feed_id
-WHERE ff.user_id = $1
-ORDER BY p.published_at DESC
-LIMIT $2;
\ No newline at end of file
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+  ))
+  AND (sqlc.narg(feed)::text IS NULL OR f.url = sqlc.narg(feed) OR lower(f.name) = lower(sqlc.narg(feed)))
+  AND (p.duplicate_of IS NULL OR sqlc.narg(feed)::text IS NOT NULL OR NOT EXISTS (
+    SELECT 1 FROM posts o
+    JOIN feed_follows off ON o.feed_id = off.feed_id
+    WHERE o.id = p.duplicate_of AND off.user_id = ff.user_id
+  ))
+  AND (sqlc.narg(since)::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < sqlc.narg(until))
+  AND (sqlc.narg(cursor_at)::timestamp IS NULL
+    OR (COALESCE(p.published_at, p.created_at), p.id) < (sqlc.narg(cursor_at), sqlc.arg(cursor_id)::uuid))
+ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC
+LIMIT sqlc.arg(post_limit)
+OFFSET sqlc.arg(post_offset);
+
+-- name: GetPostsForUserOldestFirst :many
+SELECT 
+    p.id,
+    p.created_at,
+    p.updated_at,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.content,
+    p.authors,
+    p.categories,
+    f.name AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+    ) AS is_read,
+    COALESCE(p.published_at, p.created_at)::timestamp AS sort_at
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (NOT sqlc.arg(unread_only)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_reads pr WHERE pr.user_id = ff.user_id AND pr.post_id = p.id
+  ))
+  AND (sqlc.narg(feed)::text IS NULL OR f.url = sqlc.narg(feed) OR lower(f.name) = lower(sqlc.narg(feed)))
+  AND (p.duplicate_of IS NULL OR sqlc.narg(feed)::text IS NOT NULL OR NOT EXISTS (
+    SELECT 1 FROM posts o
+    JOIN feed_follows off ON o.feed_id = off.feed_id
+    WHERE o.id = p.duplicate_of AND off.user_id = ff.user_id
+  ))
+  AND (sqlc.narg(since)::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < sqlc.narg(until))
+  AND (sqlc.narg(cursor_at)::timestamp IS NULL
+    OR (COALESCE(p.published_at, p.created_at), p.id) > (sqlc.narg(cursor_at), sqlc.arg(cursor_id)::uuid))
+ORDER BY COALESCE(p.published_at, p.created_at) ASC, p.id ASC
+LIMIT sqlc.arg(post_limit)
+OFFSET sqlc.arg(post_offset);
+
+-- name: GetPost :one
+SELECT * FROM posts WHERE id = $1 LIMIT 1;
+
+-- name: SetPostArticle :exec
+UPDATE posts
+SET article = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetPostsMissingArticle :many
+SELECT id, url FROM posts
+WHERE feed_id = $1 AND article IS NULL AND created_at > NOW() - INTERVAL '1 day'
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: DeleteMovedPostDuplicates :exec
+DELETE FROM posts p
+USING posts t
+WHERE p.feed_id = sqlc.arg(from_feed_id)
+  AND t.feed_id = sqlc.arg(to_feed_id)
+  AND (p.canonical_url = t.canonical_url OR p.guid = t.guid);
+
+-- name: MovePosts :exec
+UPDATE posts
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: SearchPosts :many
+SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.published_at,
+    p.feed_id,
+    f.name AS feed_name,
+    ts_rank(
+        setweight(to_tsvector('english', p.title), 'A') || setweight(to_tsvector('english', coalesce(p.description, '')), 'B'),
+        q
+    ) AS rank,
+    ts_headline(
+        'english',
+        coalesce(p.description, p.title),
+        q,
+        'StartSel=**, StopSel=**, MaxWords=35, MinWords=15, MaxFragments=2'
+    ) AS snippet
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id,
+    websearch_to_tsquery('english', sqlc.arg(query)) AS q
+WHERE (setweight(to_tsvector('english', p.title), 'A') || setweight(to_tsvector('english', coalesce(p.description, '')), 'B')) @@ q
+  AND (sqlc.arg(all_feeds)::boolean OR EXISTS (
+    SELECT 1 FROM feed_follows ff WHERE ff.feed_id = p.feed_id AND ff.user_id = sqlc.arg(user_id)
+  ))
+ORDER BY rank DESC, p.published_at DESC NULLS LAST
+LIMIT sqlc.arg(result_limit);
\ No newline at end of file
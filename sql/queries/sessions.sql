@@ -0,0 +1,16 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (token_hash, user_id, expires_at)
+VALUES (sqlc.arg(token_hash), sqlc.arg(user_id), NOW() + sqlc.arg(lifetime)::interval);
+
+-- name: GetUserBySessionTokenHash :one
+SELECT * FROM users
+WHERE id = (
+    SELECT user_id FROM sessions
+    WHERE token_hash = $1 AND expires_at > NOW()
+);
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token_hash = $1;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions WHERE expires_at <= NOW();
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- name: StarPost :exec
+INSERT INTO starred_posts (user_id, post_id, title, url, description, published_at, feed_name)
+SELECT $1, p.id, p.title, p.url, p.description, p.published_at, f.name
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+WHERE p.id = $2
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnstarPost :execrows
+DELETE FROM starred_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetStarredPosts :many
+SELECT * FROM starred_posts
+WHERE user_id = $1
+ORDER BY starred_at DESC
+LIMIT $2;
\ No newline at end of file
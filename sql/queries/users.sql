@@ -1,8 +1,10 @@
 -- name: CreateUser :one
-INSERT INTO users (id, name)
+INSERT INTO users (id, name, api_key_hash, password_hash)
 VALUES (
     $1,
-    $2
+    $2,
+    $3,
+    $4
 )
 RETURNING *;
 
@@ -12,6 +14,15 @@ SELECT * FROM users WHERE id = $1 LIMIT 1;
 -- name: GetUserByName :one
 SELECT * FROM users WHERE name = $1 LIMIT 1;
 
+-- name: GetUserByAPIKeyHash :one
+SELECT * FROM users WHERE api_key_hash = $1 LIMIT 1;
+
+-- name: SetUserAPIKeyHash :exec
+UPDATE users
+SET api_key_hash = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
 -- name: DeleteAllUsers :exec
 DELETE FROM users;
 
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN etag TEXT,
+ADD COLUMN last_modified TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_modified,
+DROP COLUMN etag;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN fetch_interval INTERVAL NOT NULL DEFAULT '1 hour',
+ADD COLUMN fetch_interval_override INTERVAL,
+ADD COLUMN next_fetch_at TIMESTAMP;
+
+CREATE INDEX feeds_next_fetch_at_idx ON feeds (next_fetch_at NULLS FIRST);
+
+-- +goose Down
+DROP INDEX IF EXISTS feeds_next_fetch_at_idx;
+
+ALTER TABLE feeds
+DROP COLUMN next_fetch_at,
+DROP COLUMN fetch_interval_override,
+DROP COLUMN fetch_interval;
\ No newline at end of file
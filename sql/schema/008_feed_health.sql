@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_error TEXT,
+ADD COLUMN last_success_at TIMESTAMP,
+ADD COLUMN disabled_at TIMESTAMP;
+
+CREATE TABLE feed_errors (
+    id UUID PRIMARY KEY,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    error TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX feed_errors_feed_id_created_at_idx ON feed_errors (feed_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS feed_errors;
+
+ALTER TABLE feeds
+DROP COLUMN disabled_at,
+DROP COLUMN last_success_at,
+DROP COLUMN last_error,
+DROP COLUMN consecutive_failures;
\ No newline at end of file
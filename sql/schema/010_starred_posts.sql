@@ -0,0 +1,19 @@
+-- +goose Up
+-- Starred posts keep a copy of the post's details and deliberately have no
+-- foreign key to posts, so they survive unfollows and feed deletion
+CREATE TABLE starred_posts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT,
+    published_at TIMESTAMP,
+    feed_name TEXT NOT NULL,
+    starred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX starred_posts_user_id_starred_at_idx ON starred_posts (user_id, starred_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS starred_posts;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Expression index matching the search vector used by SearchPosts
+CREATE INDEX posts_search_idx ON posts USING GIN (
+    (setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', coalesce(description, '')), 'B'))
+);
+
+-- +goose Down
+DROP INDEX IF EXISTS posts_search_idx;
\ No newline at end of file
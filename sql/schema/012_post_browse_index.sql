@@ -0,0 +1,6 @@
+-- +goose Up
+-- Supports keyset pagination over each feed's posts in either direction
+CREATE INDEX posts_feed_id_sort_idx ON posts (feed_id, (COALESCE(published_at, created_at)), id);
+
+-- +goose Down
+DROP INDEX IF EXISTS posts_feed_id_sort_idx;
\ No newline at end of file
@@ -0,0 +1,36 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN guid TEXT,
+ADD COLUMN canonical_url TEXT,
+ADD COLUMN duplicate_of UUID REFERENCES posts(id) ON DELETE SET NULL;
+
+-- Existing posts were deduplicated by their exact URL, which is the best
+-- canonical form available without reparsing them
+UPDATE posts SET canonical_url = url;
+
+ALTER TABLE posts
+ALTER COLUMN canonical_url SET NOT NULL,
+DROP CONSTRAINT posts_url_key;
+
+-- Posts are unique per feed, by GUID when the feed provides one and always by canonical URL
+CREATE UNIQUE INDEX posts_feed_guid_key ON posts (feed_id, guid) WHERE guid IS NOT NULL;
+CREATE UNIQUE INDEX posts_feed_canonical_url_key ON posts (feed_id, canonical_url);
+
+-- Finds the same article in other feeds
+CREATE INDEX posts_canonical_url_idx ON posts (canonical_url);
+
+-- +goose Down
+DROP INDEX IF EXISTS posts_canonical_url_idx;
+DROP INDEX IF EXISTS posts_feed_canonical_url_key;
+DROP INDEX IF EXISTS posts_feed_guid_key;
+
+-- Keep the oldest copy of each URL so the global constraint can be restored
+DELETE FROM posts p
+USING posts q
+WHERE p.url = q.url AND (p.created_at, p.id) > (q.created_at, q.id);
+
+ALTER TABLE posts
+ADD CONSTRAINT posts_url_key UNIQUE (url),
+DROP COLUMN duplicate_of,
+DROP COLUMN canonical_url,
+DROP COLUMN guid;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content TEXT,
+ADD COLUMN authors TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE post_enclosures (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    type TEXT,
+    length BIGINT,
+    PRIMARY KEY (post_id, url)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS post_enclosures;
+
+ALTER TABLE posts
+DROP COLUMN categories,
+DROP COLUMN authors,
+DROP COLUMN content;
\ No newline at end of file
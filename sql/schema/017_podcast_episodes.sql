@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN duration_seconds INTEGER,
+ADD COLUMN episode INTEGER,
+ADD COLUMN image_url TEXT;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN image_url,
+DROP COLUMN episode,
+DROP COLUMN duration_seconds;
\ No newline at end of file
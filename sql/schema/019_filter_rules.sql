@@ -0,0 +1,19 @@
+-- +goose Up
+-- Filter rules hide, highlight or star a user's posts whose title matches a
+-- regular expression and/or whose title or description contains a keyword,
+-- in one feed or, when feed_id is NULL, in every feed
+CREATE TABLE filter_rules (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID REFERENCES feeds(id) ON DELETE CASCADE,
+    title_regex TEXT,
+    keyword TEXT,
+    action TEXT NOT NULL CHECK (action IN ('hide', 'highlight', 'star')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (title_regex IS NOT NULL OR keyword IS NOT NULL)
+);
+
+CREATE INDEX filter_rules_user_id_idx ON filter_rules (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS filter_rules;
\ No newline at end of file
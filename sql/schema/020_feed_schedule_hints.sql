@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN publisher_min_interval INTERVAL,
+ADD COLUMN skip_hours INTEGER[];
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN skip_hours,
+DROP COLUMN publisher_min_interval;
\ No newline at end of file